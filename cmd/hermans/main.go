@@ -1,21 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/alexflint/go-arg"
 	"github.com/joho/godotenv"
 	"github.com/zekrotja/hermans/pkg/api"
 	"github.com/zekrotja/hermans/pkg/controller"
 	"github.com/zekrotja/hermans/pkg/database"
+	"github.com/zekrotja/hermans/pkg/scraper"
 )
 
 type Args struct {
-	BindAddress string     `arg:"--bind-address,env:HMS_BIND_ADDRESS" help:"Address to bind to" default:"0.0.0.0:8080"`
-	DatabaseDsn string     `arg:"--database-dsn,required,env:HMS_DATABASE_DSN" help:"Database DSN"`
-	CacheDir    string     `arg:"--cache-dir,env:HMS_CACHE_DIR" help:"Cache directory" default:"./cache"`
-	LogLevel    slog.Level `arg:"--log-level,env:HMS_LOG_LEVEL" help:"Log level" default:"info"`
+	BindAddress      string        `arg:"--bind-address,env:HMS_BIND_ADDRESS" help:"Address to bind to" default:"0.0.0.0:8080"`
+	DatabaseDsn      string        `arg:"--database-dsn,required,env:HMS_DATABASE_DSN" help:"Database DSN"`
+	CacheDir         string        `arg:"--cache-dir,env:HMS_CACHE_DIR" help:"Cache directory" default:"./cache"`
+	MenuFile         string        `arg:"--menu-file,env:HMS_MENU_FILE" help:"Serve a static menu from a JSON or YAML file instead of scraping the web shop"`
+	ScrapeInterval   time.Duration `arg:"--scrape-interval,env:HMS_SCRAPE_INTERVAL" help:"Interval in which the menu is refreshed in the background (0 to disable)" default:"6h"`
+	ScrapeTTL        time.Duration `arg:"--scrape-ttl,env:HMS_SCRAPE_TTL" help:"Maximum age of cached menu data before it is refreshed in the background on access (0 to never expire)" default:"24h"`
+	ScrapeTimeout    time.Duration `arg:"--scrape-timeout,env:HMS_SCRAPE_TIMEOUT" help:"Timeout of each request to the web shop" default:"15s"`
+	ScheduleInterval time.Duration `arg:"--schedule-check-interval,env:HMS_SCHEDULE_CHECK_INTERVAL" help:"Interval in which schedules are checked for creating new order lists (0 to disable)" default:"1m"`
+	LogLevel         slog.Level    `arg:"--log-level,env:HMS_LOG_LEVEL" help:"Log level" default:"info"`
 }
 
 func checkErr(msg string, err error, extraFields ...any) {
@@ -37,17 +50,66 @@ func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: args.LogLevel}))
 	slog.SetDefault(logger)
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	slog.Info("initializing database connection ...", "dsn", args.DatabaseDsn)
 	db, err := database.New(args.DatabaseDsn)
 	checkErr("failed initializing database", err)
 
+	var menu controller.MenuSource = scraper.New(scraper.DefaultBaseURL, &http.Client{Timeout: args.ScrapeTimeout})
+	if args.MenuFile != "" {
+		slog.Info("using static menu file", "file", args.MenuFile)
+		menu = scraper.NewFileSource(args.MenuFile)
+	}
+
 	slog.Info("initializing controller ...")
-	ctl, err := controller.New(args.CacheDir, db)
+	ctl, err := controller.New(args.CacheDir, db, menu, args.ScrapeTTL)
 	checkErr("failed initializing controller", err)
 
+	var wg sync.WaitGroup
+
+	if args.ScrapeInterval > 0 {
+		slog.Info("starting menu refresher ...", "interval", args.ScrapeInterval)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctl.RunScrapeRefresher(ctx, args.ScrapeInterval)
+		}()
+	}
+
+	if args.ScheduleInterval > 0 {
+		slog.Info("starting list scheduler ...", "interval", args.ScheduleInterval)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctl.RunScheduler(ctx, args.ScheduleInterval)
+		}()
+	}
+
 	a := api.New(ctl, args.BindAddress)
 
+	// Start returns as soon as the shutdown begins, so main waits for
+	// shutdownDone to let in-flight requests finish.
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		slog.Info("shutting down ...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := a.Shutdown(shutdownCtx); err != nil {
+			slog.Error("failed shutting down web server", "err", err)
+		}
+	}()
+
 	slog.Info("starting web server ...", "addr", args.BindAddress)
 	err = a.Start()
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
 	checkErr("failed starting web server", err)
+
+	<-shutdownDone
+	wg.Wait()
 }
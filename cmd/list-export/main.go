@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/alexflint/go-arg"
+	"github.com/joho/godotenv"
+	"github.com/zekrotja/hermans/pkg/controller"
+	"github.com/zekrotja/hermans/pkg/database"
+	"github.com/zekrotja/hermans/pkg/export"
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+type Args struct {
+	ListId      string `arg:"positional,required" help:"ID of the order list to export"`
+	Format      string `arg:"--format,-f" help:"Export format (csv, md, pdf or txt)" default:"txt"`
+	Output      string `arg:"--output,-o" help:"File to write the export to; defaults to a file named after the list for PDF and stdout otherwise"`
+	DatabaseDsn string `arg:"--database-dsn,required,env:HMS_DATABASE_DSN" help:"Database DSN"`
+	CacheDir    string `arg:"--cache-dir,env:HMS_CACHE_DIR" help:"Cache directory" default:"./cache"`
+	MenuFile    string `arg:"--menu-file,env:HMS_MENU_FILE" help:"Resolve items from a static menu file instead of the cached or scraped web shop menu"`
+}
+
+func main() {
+	godotenv.Load()
+
+	var args Args
+	arg.MustParse(&args)
+
+	if err := run(&args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args *Args) error {
+	format, err := export.ParseFormat(args.Format)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(args.DatabaseDsn)
+	if err != nil {
+		return fmt.Errorf("opening database failed: %w", err)
+	}
+
+	// Cached menu data never expires here so that the export does not
+	// depend on the web shop being reachable. A static menu file is read
+	// into a separate cache, so that it is neither shadowed by nor
+	// written into the cache of the server.
+	cacheDir := args.CacheDir
+	var menu controller.MenuSource = scraper.New(scraper.DefaultBaseURL, &http.Client{Timeout: 15 * time.Second})
+	if args.MenuFile != "" {
+		menu = scraper.NewFileSource(args.MenuFile)
+		if cacheDir, err = os.MkdirTemp("", "hermans-export-"); err != nil {
+			return fmt.Errorf("creating menu cache failed: %w", err)
+		}
+		defer os.RemoveAll(cacheDir)
+	}
+
+	ctl, err := controller.New(cacheDir, db, menu, 0)
+	if err != nil {
+		return fmt.Errorf("initializing controller failed: %w", err)
+	}
+
+	listExport, err := ctl.GetListExport(args.ListId)
+	if err != nil {
+		return fmt.Errorf("loading order list failed: %w", err)
+	}
+
+	output := args.Output
+	if output == "" && format == export.FormatPDF {
+		output = export.Filename(listExport, format)
+	}
+
+	if output == "" || output == "-" {
+		if err = export.Render(os.Stdout, format, listExport); err != nil {
+			return fmt.Errorf("rendering export failed: %w", err)
+		}
+		return nil
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("creating output file failed: %w", err)
+	}
+	err = export.Render(f, format, listExport)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		// No partially written export is left behind.
+		os.Remove(output)
+		return fmt.Errorf("rendering export failed: %w", err)
+	}
+
+	log.Printf("export written to %s", output)
+	return nil
+}
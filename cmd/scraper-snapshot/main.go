@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexflint/go-arg"
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+const goldenFile = "golden.json"
+
+type Args struct {
+	Dir    string `arg:"--dir" help:"Snapshot directory" default:"pkg/scraper/testdata"`
+	Record bool   `arg:"--record" help:"Record new snapshots from the live web shop"`
+	Update bool   `arg:"--update" help:"Overwrite the golden file with the result parsed from the snapshots"`
+}
+
+func main() {
+	var args Args
+	arg.MustParse(&args)
+
+	if args.Record {
+		s := scraper.New(scraper.DefaultBaseURL,
+			&http.Client{Transport: scraper.NewRecordTransport(args.Dir, nil)})
+		if _, err := s.ScrapeAll(context.Background()); err != nil {
+			log.Fatalf("recording snapshots failed: %v", err)
+		}
+		args.Update = true
+	}
+
+	s := scraper.New(scraper.DefaultBaseURL,
+		&http.Client{Transport: scraper.NewReplayTransport(args.Dir)})
+	data, err := s.ScrapeAll(context.Background())
+	if err != nil {
+		log.Fatalf("parsing snapshots failed: %v", err)
+	}
+
+	actual, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		log.Fatalf("encoding result failed: %v", err)
+	}
+	actual = append(actual, '\n')
+
+	goldenPath := filepath.Join(args.Dir, goldenFile)
+
+	if args.Update {
+		if err = os.WriteFile(goldenPath, actual, 0644); err != nil {
+			log.Fatalf("writing golden file failed: %v", err)
+		}
+		fmt.Printf("golden file written to %s\n", goldenPath)
+		return
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		log.Fatalf("reading golden file failed: %v", err)
+	}
+
+	if bytes.Equal(expected, actual) {
+		fmt.Println("snapshots match golden file")
+		return
+	}
+
+	expLines := strings.Split(string(expected), "\n")
+	actLines := strings.Split(string(actual), "\n")
+	for i := 0; i < max(len(expLines), len(actLines)); i++ {
+		var exp, act string
+		if i < len(expLines) {
+			exp = expLines[i]
+		}
+		if i < len(actLines) {
+			act = actLines[i]
+		}
+		if exp != act {
+			fmt.Printf("mismatch in line %d:\n  expected: %s\n  actual:   %s\n", i+1, exp, act)
+			break
+		}
+	}
+	os.Exit(1)
+}
@@ -1,21 +1,45 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
 	"net/http"
+	"strconv"
 
+	"github.com/zekrotja/hermans/pkg/export"
+	"github.com/zekrotja/hermans/pkg/hub"
 	"github.com/zekrotja/hermans/pkg/model"
 )
 
 type API struct {
 	ctl    Controller
+	hub    *hub.Hub
 	server *http.Server
+
+	// baseCtx is the parent context of all requests. It is canceled on
+	// shutdown to end long-lived requests like event streams.
+	baseCtx    context.Context
+	cancelBase context.CancelFunc
 }
 
 func New(ctl Controller, addr string) *API {
 	mux := http.NewServeMux()
+	baseCtx, cancelBase := context.WithCancel(context.Background())
 	t := API{
-		ctl:    ctl,
-		server: &http.Server{Addr: addr, Handler: mux},
+		ctl:        ctl,
+		hub:        hub.New(ctl, 32),
+		baseCtx:    baseCtx,
+		cancelBase: cancelBase,
+	}
+	t.server = &http.Server{
+		Addr:        addr,
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return t.baseCtx },
 	}
 
 	mux.Handle("/", http.FileServer(http.Dir("webapp")))
@@ -23,13 +47,26 @@ func New(ctl Controller, addr string) *API {
 	// API Routen
 	mux.HandleFunc("OPTIONS /", t.handleOptions)
 	mux.HandleFunc("GET /api/items", multiHandler(t.setCORSHeader, t.handleGetStoreItems))
+	mux.HandleFunc("GET /api/lists", multiHandler(t.setCORSHeader, t.handleListOrderLists))
 	mux.HandleFunc("POST /api/lists", multiHandler(t.setCORSHeader, t.handleCreateOrderList))
 	mux.HandleFunc("GET /api/lists/{id}", multiHandler(t.setCORSHeader, t.handleGetOrderList))
 	mux.HandleFunc("DELETE /api/lists/{id}", multiHandler(t.setCORSHeader, t.handleDeleteOrderList))
+	mux.HandleFunc("PATCH /api/lists/{id}", multiHandler(t.setCORSHeader, t.handleUpdateOrderList))
+	mux.HandleFunc("PUT /api/lists/{id}/state", multiHandler(t.setCORSHeader, t.handleSetListState))
+	mux.HandleFunc("GET /api/lists/{id}/summary", multiHandler(t.setCORSHeader, t.handleGetListSummary))
+	mux.HandleFunc("GET /api/lists/{id}/export", multiHandler(t.setCORSHeader, t.handleExportList))
+	mux.HandleFunc("POST /api/lists/{id}/surprises", multiHandler(t.setCORSHeader, t.handleResolveSurprises))
+	mux.HandleFunc("GET /api/lists/{id}/events", multiHandler(t.setCORSHeader, t.handleListEvents))
+	mux.HandleFunc("GET /api/lists/{id}/ws", multiHandler(t.setCORSHeader, t.handleListWebSocket))
 	mux.HandleFunc("POST /api/lists/{id}/orders", multiHandler(t.setCORSHeader, t.handleCreateOrder))
 	mux.HandleFunc("PUT /api/lists/{listId}/orders/{orderId}", multiHandler(t.setCORSHeader, t.handleUpdateOrder))
 	mux.HandleFunc("DELETE /api/lists/{listId}/orders/{orderId}", multiHandler(t.setCORSHeader, t.handleDeleteOrder))
 	mux.HandleFunc("GET /api/lists/{listId}/orders/{orderId}", multiHandler(t.setCORSHeader, t.handleGetOrder))
+	mux.HandleFunc("PATCH /api/lists/{listId}/orders/{orderId}/payment", multiHandler(t.setCORSHeader, t.handleUpdatePayment))
+	mux.HandleFunc("POST /api/schedules", multiHandler(t.setCORSHeader, t.handleCreateSchedule))
+	mux.HandleFunc("GET /api/schedules/{id}", multiHandler(t.setCORSHeader, t.handleGetSchedule))
+	mux.HandleFunc("DELETE /api/schedules/{id}", multiHandler(t.setCORSHeader, t.handleDeleteSchedule))
+	mux.HandleFunc("GET /api/schedules/{id}/current", multiHandler(t.setCORSHeader, t.handleGetCurrentScheduleList))
 	mux.HandleFunc("POST /api/feedback", multiHandler(t.setCORSHeader, t.handleCreateFeedback))
 	mux.HandleFunc("GET /api/dev/clearall", multiHandler(t.setCORSHeader, t.handleClearAll))
 
@@ -40,6 +77,13 @@ func (t *API) Start() error {
 	return t.server.ListenAndServe()
 }
 
+// Shutdown gracefully stops the web server, waiting for active
+// requests to finish until ctx is done.
+func (t *API) Shutdown(ctx context.Context) error {
+	t.cancelBase()
+	return t.server.Shutdown(ctx)
+}
+
 func (t *API) handleOptions(w http.ResponseWriter, r *http.Request) {
 	t.setCORSHeader(w, r)
 	w.WriteHeader(http.StatusNoContent)
@@ -64,16 +108,38 @@ func (t *API) handleGetStoreItems(w http.ResponseWriter, r *http.Request) {
 
 func (t *API) handleCreateOrderList(w http.ResponseWriter, r *http.Request) {
 	payload, err := readJsonBody[model.CreateListPayload](r)
-	if err != nil && err.Error() != "EOF" {
+	if err != nil && !errors.Is(err, io.EOF) {
+		respondErr(w, err)
+		return
+	}
+	list, err := t.ctl.CreateOrderList(&payload)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	response := model.CreateOrderListResponse{
+		Id:            list.Id,
+		Created:       list.Created,
+		ListInfo:      list.ListInfo,
+		Deadline:      list.Deadline,
+		State:         list.State,
+		ManagementKey: list.ManagementKey,
+	}
+	respondJson(w, http.StatusCreated, response)
+}
+
+func (t *API) handleListOrderLists(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseListFilter(r.URL.Query())
+	if err != nil {
 		respondErr(w, err)
 		return
 	}
-	list, err := t.ctl.CreateOrderList(payload.Deadline)
+	lists, next, err := t.ctl.ListOrderLists(filter)
 	if err != nil {
 		respondErr(w, err)
 		return
 	}
-	respondJson(w, http.StatusCreated, list)
+	respondJson(w, http.StatusOK, model.ListOrderListsResponse{Lists: lists, NextCursor: next})
 }
 
 func (t *API) handleGetOrderList(w http.ResponseWriter, r *http.Request) {
@@ -83,20 +149,71 @@ func (t *API) handleGetOrderList(w http.ResponseWriter, r *http.Request) {
 		respondErr(w, err)
 		return
 	}
-	orders, err := t.ctl.GetOrders(orderListId)
+	t.respondOrderList(w, list)
+}
+
+func (t *API) respondOrderList(w http.ResponseWriter, list *model.OrderList) {
+	orders, err := t.ctl.GetOrders(list.Id)
 	if err != nil {
 		respondErr(w, err)
 		return
 	}
+	totalCents, totalIncomplete := model.SumTotals(orders)
 	response := model.GetOrderListResponse{
-		Id:       list.Id,
-		Created:  list.Created,
-		Deadline: list.Deadline,
-		Orders:   orders,
+		Id:              list.Id,
+		Created:         list.Created,
+		ListInfo:        list.ListInfo,
+		Deadline:        list.Deadline,
+		State:           list.State,
+		ScheduleId:      list.ScheduleId,
+		Orders:          orders,
+		TotalCents:      totalCents,
+		TotalIncomplete: totalIncomplete,
+		Payments:        model.SummarizePayments(orders),
 	}
 	respondJson(w, http.StatusOK, response)
 }
 
+func (t *API) handleGetListSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := t.ctl.GetListSummary(r.PathValue("id"))
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	respondJson(w, http.StatusOK, summary)
+}
+
+func (t *API) handleExportList(w http.ResponseWriter, r *http.Request) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+
+	listExport, err := t.ctl.GetListExport(r.PathValue("id"))
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+
+	// The export is rendered into a buffer first so that an error can
+	// still be responded as JSON.
+	var buf bytes.Buffer
+	if err = export.Render(&buf, format, listExport); err != nil {
+		respondErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": export.Filename(listExport, format)}))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	if _, err = buf.WriteTo(w); err != nil {
+		slog.Error("failed writing export response", "err", err)
+	}
+}
+
 func (t *API) handleGetOrder(w http.ResponseWriter, r *http.Request) {
 	listId := r.PathValue("listId")
 	orderId := r.PathValue("orderId")
@@ -125,7 +242,9 @@ func (t *API) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
 		Created:    newOrder.Created,
 		Creator:    newOrder.Creator,
 		StoreItems: newOrder.StoreItems,
+		Drinks:     newOrder.Drinks,
 		Drink:      newOrder.Drink,
+		Note:       newOrder.Note,
 		EditKey:    newOrder.EditKey,
 	}
 	respondJson(w, http.StatusCreated, response)
@@ -139,7 +258,7 @@ func (t *API) handleUpdateOrder(w http.ResponseWriter, r *http.Request) {
 		respondErr(w, err)
 		return
 	}
-	updatedOrder, err := t.ctl.UpdateOrder(listId, orderId, payload.EditKey, &payload.Order)
+	updatedOrder, err := t.ctl.UpdateOrder(listId, orderId, payload.EditKey, payload.ManagementKey, &payload.Order)
 	if err != nil {
 		respondErr(w, err)
 		return
@@ -155,22 +274,140 @@ func (t *API) handleDeleteOrder(w http.ResponseWriter, r *http.Request) {
 		respondErr(w, err)
 		return
 	}
-	if err := t.ctl.DeleteOrder(listId, orderId, payload.EditKey); err != nil {
+	if err := t.ctl.DeleteOrder(listId, orderId, payload.EditKey, payload.ManagementKey); err != nil {
 		respondErr(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (t *API) handleUpdatePayment(w http.ResponseWriter, r *http.Request) {
+	listId := r.PathValue("listId")
+	orderId := r.PathValue("orderId")
+	payload, err := readJsonBody[model.UpdatePaymentPayload](r)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	order, err := t.ctl.UpdatePayment(listId, orderId, payload.ManagementKey, &payload.Payment)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	respondJson(w, http.StatusOK, order)
+}
+
 func (t *API) handleDeleteOrderList(w http.ResponseWriter, r *http.Request) {
 	orderListId := r.PathValue("id")
-	if err := t.ctl.DeleteOrderList(orderListId); err != nil {
+	// A request without body is answered like one without key.
+	payload, err := readJsonBody[model.DeleteListPayload](r)
+	if err != nil && !errors.Is(err, io.EOF) {
+		respondErr(w, err)
+		return
+	}
+	if err := t.ctl.DeleteOrderList(orderListId, payload.ManagementKey); err != nil {
+		respondErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *API) handleUpdateOrderList(w http.ResponseWriter, r *http.Request) {
+	orderListId := r.PathValue("id")
+	payload, err := readJsonBody[model.UpdateListPayload](r)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	list, err := t.ctl.UpdateOrderList(orderListId, payload.ManagementKey, &payload)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	respondJson(w, http.StatusOK, list)
+}
+
+func (t *API) handleResolveSurprises(w http.ResponseWriter, r *http.Request) {
+	orderListId := r.PathValue("id")
+	payload, err := readJsonBody[model.ResolveSurprisesPayload](r)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	orders, err := t.ctl.ResolveSurprises(orderListId, payload.ManagementKey, payload.Seed)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	respondJson(w, http.StatusOK, orders)
+}
+
+func (t *API) handleSetListState(w http.ResponseWriter, r *http.Request) {
+	orderListId := r.PathValue("id")
+	payload, err := readJsonBody[model.UpdateListStatePayload](r)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	list, err := t.ctl.SetListState(orderListId, payload.ManagementKey, payload.State)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	respondJson(w, http.StatusOK, list)
+}
+
+func (t *API) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := readJsonBody[model.Schedule](r)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	newSchedule, err := t.ctl.CreateSchedule(&schedule)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	response := model.CreateScheduleResponse{
+		Schedule:      *newSchedule,
+		ManagementKey: newSchedule.ManagementKey,
+	}
+	respondJson(w, http.StatusCreated, response)
+}
+
+func (t *API) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := t.ctl.GetSchedule(r.PathValue("id"))
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	respondJson(w, http.StatusOK, schedule)
+}
+
+func (t *API) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	payload, err := readJsonBody[model.DeleteSchedulePayload](r)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	if err := t.ctl.DeleteSchedule(r.PathValue("id"), payload.ManagementKey); err != nil {
 		respondErr(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleGetCurrentScheduleList responds with the latest order list created
+// from the schedule, so that the URL can be shared once for all lists.
+func (t *API) handleGetCurrentScheduleList(w http.ResponseWriter, r *http.Request) {
+	list, err := t.ctl.GetCurrentScheduleList(r.PathValue("id"))
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	t.respondOrderList(w, list)
+}
+
 func (t *API) handleCreateFeedback(w http.ResponseWriter, r *http.Request) {
 	feedback, err := readJsonBody[model.Feedback](r)
 	if err != nil {
@@ -188,5 +425,5 @@ func (t *API) handleCreateFeedback(w http.ResponseWriter, r *http.Request) {
 func (t *API) setCORSHeader(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-control-allow-headers", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 }
@@ -5,8 +5,11 @@ import (
 )
 
 const (
-	ErrParseJsonBody = elk.ErrorCode("api:parse-json-body")
-	ErrValidation    = elk.ErrorCode("api:validation")
+	ErrParseJsonBody        = elk.ErrorCode("api:parse-json-body")
+	ErrValidation           = elk.ErrorCode("api:validation")
+	ErrInvalidEventId       = elk.ErrorCode("api:invalid-event-id")
+	ErrInvalidQuery         = elk.ErrorCode("api:invalid-query")
+	ErrStreamingUnsupported = elk.ErrorCode("api:streaming-unsupported")
 )
 
 type ValidationError struct {
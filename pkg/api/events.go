@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/studio-b12/elk"
+	"github.com/zekrotja/hermans/pkg/events"
+)
+
+const sseHeartbeatInterval = 30 * time.Second
+
+// handleListEvents streams the events of an order list as Server-Sent
+// Events. Clients can resume a stream by passing the id of the last
+// received event via the Last-Event-ID header or the lastEventId
+// query parameter.
+func (t *API) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	orderListId := r.PathValue("id")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondErr(w, elk.NewError(ErrStreamingUnsupported, "streaming is not supported"))
+		return
+	}
+
+	lastEventIdStr := r.Header.Get("Last-Event-ID")
+	if lastEventIdStr == "" {
+		lastEventIdStr = r.URL.Query().Get("lastEventId")
+	}
+	var lastEventId uint64
+	if lastEventIdStr != "" {
+		var err error
+		lastEventId, err = strconv.ParseUint(lastEventIdStr, 10, 64)
+		if err != nil {
+			respondErr(w, elk.Wrap(ErrInvalidEventId, err, "invalid last event id"))
+			return
+		}
+	}
+
+	sub, missed, err := t.ctl.SubscribeListEvents(orderListId, lastEventId)
+	if err != nil {
+		respondErr(w, err)
+		return
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for _, ev := range missed {
+		if err = writeSSEEvent(w, ev); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err = writeSSEEvent(w, ev); err != nil {
+				return
+			}
+			flusher.Flush()
+			if ev.Type == events.ListDeleted {
+				return
+			}
+		}
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, ev *events.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Id, ev.Type, data)
+	return err
+}
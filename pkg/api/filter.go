@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/studio-b12/elk"
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+const defaultListLimit = 20
+
+// parseListFilter reads the filter of an order list listing from the
+// query parameters status, created_after, created_before, has_deadline,
+// sort, order, limit and cursor.
+func parseListFilter(query url.Values) (*model.ListFilter, error) {
+	filter := model.ListFilter{
+		Status: model.ListStatus(query.Get("status")),
+		SortBy: model.ListSortCreated,
+		Limit:  defaultListLimit,
+	}
+
+	var err error
+	if v := query.Get("created_after"); v != "" {
+		if filter.CreatedAfter, err = parseQueryTime(v); err != nil {
+			return nil, invalidQuery("created_after", err)
+		}
+	}
+	if v := query.Get("created_before"); v != "" {
+		if filter.CreatedBefore, err = parseQueryTime(v); err != nil {
+			return nil, invalidQuery("created_before", err)
+		}
+	}
+	if v := query.Get("has_deadline"); v != "" {
+		hasDeadline, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, invalidQuery("has_deadline", err)
+		}
+		filter.HasDeadline = &hasDeadline
+	}
+	if v := query.Get("sort"); v != "" {
+		filter.SortBy = model.ListSortField(v)
+	}
+	switch query.Get("order") {
+	case "", "desc":
+		filter.Descending = true
+	case "asc":
+	default:
+		return nil, elk.NewError(ErrInvalidQuery, "invalid query parameter order: must be asc or desc")
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return nil, invalidQuery("limit", err)
+		}
+	}
+	if v := query.Get("cursor"); v != "" {
+		if filter.After, err = model.ParseListCursor(v); err != nil {
+			return nil, invalidQuery("cursor", err)
+		}
+	}
+
+	return &filter, nil
+}
+
+func parseQueryTime(v string) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func invalidQuery(param string, err error) error {
+	return elk.Wrapf(ErrInvalidQuery, err, "invalid query parameter %s", param)
+}
@@ -1,22 +1,33 @@
 package api
 
 import (
-	"time"
-
+	"github.com/zekrotja/hermans/pkg/events"
 	"github.com/zekrotja/hermans/pkg/model"
 	"github.com/zekrotja/hermans/pkg/scraper"
 )
 
 type Controller interface {
 	GetScrapedData() (*scraper.Data, error)
-	CreateOrderList(deadline *time.Time) (*model.OrderList, error)
+	CreateOrderList(payload *model.CreateListPayload) (*model.OrderList, error)
 	GetOrderList(orderListId string) (*model.OrderList, error)
-	DeleteOrderList(orderListId string) error
+	ListOrderLists(filter *model.ListFilter) ([]*model.OrderListSummary, string, error)
+	DeleteOrderList(orderListId, managementKey string) error
+	UpdateOrderList(orderListId, managementKey string, update *model.UpdateListPayload) (*model.OrderList, error)
+	SetListState(orderListId, managementKey string, state model.ListState) (*model.OrderList, error)
 	CreateOrder(orderListId string, order *model.Order) (*model.Order, error)
-	UpdateOrder(orderListId, orderId, editKey string, updatedOrder *model.Order) (*model.Order, error)
-	DeleteOrder(orderListId, orderId, editKey string) error
+	UpdateOrder(orderListId, orderId, editKey, managementKey string, updatedOrder *model.Order) (*model.Order, error)
+	DeleteOrder(orderListId, orderId, editKey, managementKey string) error
+	UpdatePayment(orderListId, orderId, managementKey string, payment *model.Payment) (*model.Order, error)
 	GetOrders(orderListId string) ([]*model.Order, error)
+	GetListSummary(orderListId string) (*model.ListSummary, error)
+	GetListExport(orderListId string) (*model.ListExport, error)
+	ResolveSurprises(orderListId, managementKey string, seed *uint64) ([]*model.Order, error)
 	GetOrder(orderListId, orderId string) (*model.Order, error)
+	SubscribeListEvents(orderListId string, lastEventId uint64) (*events.Subscription, []*events.Event, error)
+	CreateSchedule(schedule *model.Schedule) (*model.Schedule, error)
+	GetSchedule(scheduleId string) (*model.Schedule, error)
+	DeleteSchedule(scheduleId, managementKey string) error
+	GetCurrentScheduleList(scheduleId string) (*model.OrderList, error)
 	ClearAllData() error
 	// Feedback \\
 	CreateFeedback(feedback *model.Feedback) (*model.Feedback, error)
@@ -11,6 +11,7 @@ import (
 	"github.com/studio-b12/elk"
 	"github.com/zekrotja/hermans/pkg/controller"
 	"github.com/zekrotja/hermans/pkg/database"
+	"github.com/zekrotja/hermans/pkg/export"
 )
 
 func multiHandler(handler ...http.HandlerFunc) http.HandlerFunc {
@@ -67,12 +68,37 @@ func respondErr(w http.ResponseWriter, err error) {
 			eErr.ToResponseModel(http.StatusNotFound))
 		return
 	case ErrParseJsonBody,
+		ErrInvalidEventId,
+		ErrInvalidQuery,
+		export.ErrUnsupportedFormat,
+		controller.ErrInvalidDeadline,
+		controller.ErrInvalidSchedule,
 		controller.ErrInvalidDips,
+		controller.ErrInvalidDrink,
 		controller.ErrInvalidVariants,
 		controller.ErrInvalidStoreItem:
 		respondJson(w, http.StatusBadRequest,
 			eErr.ToResponseModel(http.StatusBadRequest))
 		return
+	case controller.ErrInvalidEditKey,
+		controller.ErrInvalidListKey:
+		respondJson(w, http.StatusForbidden,
+			eErr.ToResponseModel(http.StatusForbidden))
+		return
+	case controller.ErrDeadlineExceeded,
+		controller.ErrInvalidState:
+		respondJson(w, http.StatusConflict,
+			eErr.ToResponseModel(http.StatusConflict))
+		return
+	case controller.ErrListClosed:
+		respondJson(w, http.StatusGone,
+			eErr.ToResponseModel(http.StatusGone))
+		return
+	case controller.ErrMenuUnavailable:
+		slog.Warn("menu unavailable", "err", fmt.Sprintf("%v", eErr))
+		respondJson(w, http.StatusServiceUnavailable,
+			eErr.ToResponseModel(http.StatusServiceUnavailable))
+		return
 	}
 
 	callFrame, _ := eErr.CallStack().First()
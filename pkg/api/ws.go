@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/coder/websocket"
+	"github.com/studio-b12/elk"
+	"github.com/zekrotja/hermans/pkg/hub"
+)
+
+const (
+	wsWriteTimeout  = 10 * time.Second
+	wsPingInterval  = 30 * time.Second
+	wsReadLimit     = 4 * 1024
+	wsMaxNameLength = 64
+)
+
+// handleListWebSocket connects a client to the collaboration hub of an
+// order list. The client receives the mutation events of the list as
+// well as presence and typing updates of the other connected clients.
+// An optional display name can be passed via the name query parameter.
+// If the list can not be joined, the connection is closed with a policy
+// violation status and the error code as reason.
+func (t *API) handleListWebSocket(w http.ResponseWriter, r *http.Request) {
+	orderListId := r.PathValue("id")
+
+	name := r.URL.Query().Get("name")
+	if utf8.RuneCountInString(name) > wsMaxNameLength {
+		name = string([]rune(name)[:wsMaxNameLength])
+	}
+
+	// The connection is upgraded before joining so that failed upgrades
+	// are never announced to the other clients of the list.
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		OriginPatterns: []string{"*"},
+	})
+	if err != nil {
+		slog.Debug("websocket upgrade failed", "err", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	client, err := t.hub.Join(orderListId, name)
+	if err != nil {
+		slog.Debug("joining hub failed", "list", orderListId, "err", err)
+		conn.Close(websocket.StatusPolicyViolation, string(elk.Cast(err).Code()))
+		return
+	}
+	defer t.hub.Leave(client)
+
+	conn.SetReadLimit(wsReadLimit)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	go func() {
+		defer cancel()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			var msg hub.ClientMessage
+			if err = json.Unmarshal(data, &msg); err != nil {
+				continue
+			}
+			t.hub.HandleMessage(client, &msg)
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			conn.Close(websocket.StatusGoingAway, "")
+			return
+		case <-ping.C:
+			if err = wsWithTimeout(ctx, conn.Ping); err != nil {
+				return
+			}
+		case data, ok := <-client.Send():
+			if !ok {
+				conn.Close(websocket.StatusTryAgainLater, "disconnected by server")
+				return
+			}
+			err = wsWithTimeout(ctx, func(ctx context.Context) error {
+				return conn.Write(ctx, websocket.MessageText, data)
+			})
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					slog.Debug("websocket write failed", "err", err)
+				}
+				return
+			}
+		}
+	}
+}
+
+func wsWithTimeout(ctx context.Context, f func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+	defer cancel()
+	return f(ctx)
+}
@@ -1,22 +1,60 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/brunoga/deep"
 	"github.com/studio-b12/elk"
 	"github.com/vmihailenco/msgpack"
 )
 
+// Meta contains information about the value persisted in a LocalCache.
+type Meta struct {
+	StoredAt      time.Time
+	SchemaVersion int
+	Checksum      string
+}
+
+// Expired returns true if the value has been stored longer than ttl ago
+// or if no value has been stored at all. A ttl of zero or less never
+// expires a stored value.
+func (t Meta) Expired(ttl time.Duration) bool {
+	if t.StoredAt.IsZero() {
+		return true
+	}
+	return ttl > 0 && time.Since(t.StoredAt) > ttl
+}
+
+// envelope is the structure written to the cache file. The value is
+// encoded separately so that it is only decoded when the schema version
+// matches the expected one.
+type envelope struct {
+	StoredAt      time.Time
+	SchemaVersion int
+	Checksum      string
+	Data          []byte
+}
+
 type LocalCache[T any] struct {
-	mtx  sync.RWMutex
-	data T
-	dir  string
+	mtx           sync.RWMutex
+	data          T
+	meta          Meta
+	dir           string
+	schemaVersion int
 }
 
-func OpenLocalCache[T any](dir string) (*LocalCache[T], error) {
+// OpenLocalCache opens the cache file at dir. schemaVersion identifies
+// the layout of T; persisted values stored with a different schema
+// version are discarded and the cache starts empty.
+func OpenLocalCache[T any](dir string, schemaVersion int) (*LocalCache[T], error) {
 	baseDir := filepath.Dir(dir)
 	stat, err := os.Stat(baseDir)
 	if os.IsNotExist(err) {
@@ -31,7 +69,8 @@ func OpenLocalCache[T any](dir string) (*LocalCache[T], error) {
 	}
 
 	t := &LocalCache[T]{
-		dir: dir,
+		dir:           dir,
+		schemaVersion: schemaVersion,
 	}
 
 	f, err := os.Open(dir)
@@ -41,44 +80,166 @@ func OpenLocalCache[T any](dir string) (*LocalCache[T], error) {
 	if err != nil {
 		return nil, elk.Wrap(ErrFile, err, "failed to open cache file")
 	}
-	defer f.Close()
+	err = t.read(f)
+	f.Close()
 
-	err = msgpack.NewDecoder(f).Decode(&t.data)
+	// A corrupt cache file, for example left over by a crash during a
+	// write of an older version, must not prevent the application from
+	// starting. The file is moved aside for inspection and the cache
+	// starts empty.
 	if err != nil {
-		return nil, elk.Wrap(ErrDecode, err, "failed to decode cache file")
+		quarantined := fmt.Sprintf("%s.corrupt-%d", dir, time.Now().Unix())
+		slog.Warn("cache file is corrupt; moving it aside and starting with an empty cache",
+			"file", dir, "movedTo", quarantined, "err", err)
+		if err = os.Rename(dir, quarantined); err != nil {
+			return nil, elk.Wrap(ErrFile, err, "failed to move corrupt cache file")
+		}
 	}
 
 	return t, nil
 }
 
+func (t *LocalCache[T]) read(r io.Reader) error {
+	var env envelope
+	err := msgpack.NewDecoder(r).Decode(&env)
+	if err != nil {
+		return elk.Wrap(ErrDecode, err, "failed to decode cache file")
+	}
+
+	if env.SchemaVersion != t.schemaVersion {
+		return nil
+	}
+
+	if checksum(env.Data) != env.Checksum {
+		return elk.NewError(ErrDecode, "cache file checksum mismatch")
+	}
+
+	var data T
+	err = msgpack.Unmarshal(env.Data, &data)
+	if err != nil {
+		return elk.Wrap(ErrDecode, err, "failed to decode cache file")
+	}
+
+	t.data = data
+	t.meta = Meta{
+		StoredAt:      env.StoredAt,
+		SchemaVersion: env.SchemaVersion,
+		Checksum:      env.Checksum,
+	}
+
+	return nil
+}
+
 func (t *LocalCache[T]) Store(data T) error {
 	t.mtx.Lock()
 	defer t.mtx.Unlock()
 
-	t.data = data
+	// The stored value is copied so that later modifications of data
+	// by the caller do not leak into the cache.
+	dataCopy, err := deep.Copy(data)
+	if err != nil {
+		return elk.Wrap(ErrDeepCopy, err, "failed to deep copy data")
+	}
+
+	raw, err := msgpack.Marshal(dataCopy)
+	if err != nil {
+		return elk.Wrap(ErrEncode, err, "failed to encode data")
+	}
+
+	env := envelope{
+		StoredAt:      time.Now(),
+		SchemaVersion: t.schemaVersion,
+		Checksum:      checksum(raw),
+		Data:          raw,
+	}
+
+	err = t.writeFile(env)
+	if err != nil {
+		return err
+	}
 
-	f, err := os.Create(t.dir)
+	t.data = dataCopy
+	t.meta = Meta{
+		StoredAt:      env.StoredAt,
+		SchemaVersion: env.SchemaVersion,
+		Checksum:      env.Checksum,
+	}
+
+	return nil
+}
+
+// writeFile writes env to a temporary file next to the cache file and
+// renames it to the cache file afterwards, so that the cache file
+// always contains either the previous or the new value completely.
+func (t *LocalCache[T]) writeFile(env envelope) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(t.dir), filepath.Base(t.dir)+".tmp-*")
 	if err != nil {
-		return elk.Wrap(ErrFile, err, "failed to open cache file for write")
+		return elk.Wrap(ErrFile, err, "failed to create temporary cache file")
 	}
-	defer f.Close()
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
 
-	err = msgpack.NewEncoder(f).Encode(t.data)
+	if err = f.Chmod(0644); err != nil {
+		return elk.Wrap(ErrFile, err, "failed to set permissions of temporary cache file")
+	}
+	err = msgpack.NewEncoder(f).Encode(env)
 	if err != nil {
 		return elk.Wrap(ErrEncode, err, "failed to encode data to file")
 	}
+	if err = f.Sync(); err != nil {
+		return elk.Wrap(ErrFile, err, "failed to sync temporary cache file")
+	}
+	if err = f.Close(); err != nil {
+		return elk.Wrap(ErrFile, err, "failed to close temporary cache file")
+	}
+	if err = os.Rename(f.Name(), t.dir); err != nil {
+		return elk.Wrap(ErrFile, err, "failed to replace cache file")
+	}
+
+	// Syncing the directory persists the rename itself. Not all
+	// platforms support this, so failures are ignored.
+	if d, dErr := os.Open(filepath.Dir(t.dir)); dErr == nil {
+		d.Sync()
+		d.Close()
+	}
 
 	return nil
 }
 
 func (t *LocalCache[T]) Load() (v T, err error) {
+	v, _, err = t.LoadWithMeta()
+	return v, err
+}
+
+// LoadWithMeta returns a copy of the cached value together with its
+// metadata. If no value is cached, the zero value of T and empty
+// metadata are returned.
+func (t *LocalCache[T]) LoadWithMeta() (v T, meta Meta, err error) {
 	t.mtx.RLock()
 	defer t.mtx.RUnlock()
 
 	v, err = deep.Copy(t.data)
 	if err != nil {
-		return v, elk.Wrap(ErrDeepCopy, err, "failed to deep copy internal data")
+		return v, meta, elk.Wrap(ErrDeepCopy, err, "failed to deep copy internal data")
 	}
 
-	return v, nil
+	return v, t.meta, nil
+}
+
+// Expired returns true if no value is cached or if the cached value
+// has been stored longer than ttl ago.
+func (t *LocalCache[T]) Expired(ttl time.Duration) bool {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	return t.meta.Expired(ttl)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
@@ -1,45 +1,70 @@
 package controller
 
 import (
-	"errors"
+	"context"
+	"crypto/subtle"
+	"log/slog"
 	"path/filepath"
 	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/studio-b12/elk"
 	"github.com/zekrotja/hermans/pkg/cache"
+	"github.com/zekrotja/hermans/pkg/events"
 	"github.com/zekrotja/hermans/pkg/model"
 	"github.com/zekrotja/hermans/pkg/scraper"
+	"golang.org/x/sync/singleflight"
 )
 
+// scrapeDataSchemaVersion must be increased whenever the structure of
+// scraper.Data changes so that cached data of older versions is
+// discarded instead of being decoded into the new structure.
+const scrapeDataSchemaVersion = 4
+
 type Controller struct {
-	db Database
+	db   Database
+	menu MenuSource
 
 	validator *validator.Validate
 
-	scrapeCache *cache.LocalCache[*scraper.Data]
+	scrapeCache   *cache.LocalCache[*scraper.Data]
+	scrapeTTL     time.Duration
+	scrapeGroup   singleflight.Group
+	scrapeBackoff backoff
+	refreshing    atomic.Bool
+
+	events *events.Bus
 }
 
-func New(cacheDir string, db Database) (*Controller, error) {
-	scrapeDb, err := cache.OpenLocalCache[*scraper.Data](filepath.Join(cacheDir, "scrape_data.msgpack"))
+func New(cacheDir string, db Database, menu MenuSource, scrapeTTL time.Duration) (*Controller, error) {
+	scrapeDb, err := cache.OpenLocalCache[*scraper.Data](
+		filepath.Join(cacheDir, "scrape_data.msgpack"), scrapeDataSchemaVersion)
 	if err != nil {
 		return nil, err
 	}
 
 	t := &Controller{
 		db:          db,
+		menu:        menu,
 		scrapeCache: scrapeDb,
+		scrapeTTL:   scrapeTTL,
 		validator:   validator.New(validator.WithRequiredStructEnabled()),
+		events:      events.NewBus(100, 32, 24*time.Hour),
 	}
 	return t, nil
 }
 
-func (t *Controller) Scrape() (*scraper.Data, error) {
-	data, err := scraper.ScrapeAll()
+func (t *Controller) Scrape(ctx context.Context) (*scraper.Data, error) {
+	data, err := t.menu.Fetch(ctx)
 	if err != nil {
-		return nil, err
+		return nil, elk.Wrap(ErrMenuUnavailable, err, "failed fetching menu")
+	}
+
+	for _, p := range data.UnparsedPrices {
+		slog.Warn("failed parsing menu price", "kind", p.Kind, "id", p.Id, "name", p.Name, "price", p.Price)
 	}
 
 	err = t.scrapeCache.Store(data)
@@ -51,34 +76,42 @@ func (t *Controller) Scrape() (*scraper.Data, error) {
 }
 
 func (t *Controller) GetScrapedData() (*scraper.Data, error) {
-	data, err := t.scrapeCache.Load()
+	data, meta, err := t.scrapeCache.LoadWithMeta()
 	if err != nil {
 		return nil, err
 	}
 
+	// Without any cached data, the request has to wait for the menu.
+	// Schema-mismatched entries are dropped by the cache and end up here
+	// as well. Expired data also counts as a miss and triggers a scrape,
+	// but it is served until the scrape has finished in the background,
+	// so that requests neither wait for nor fail with a slow or
+	// unreachable web shop.
 	if data == nil {
-		data, err = t.Scrape()
+		data, err = t.scrapeShared()
 		if err != nil {
 			return nil, err
 		}
+	} else if meta.Expired(t.scrapeTTL) {
+		t.refreshInBackground(meta.StoredAt)
 	}
 
 	surpriseCat := []*scraper.Category{
 		{
-			Id:   "__etc",
+			Id:   surpriseCategoryId,
 			Name: "Etc",
 			Items: []*scraper.StoreItem{
 				{
-					Id:          "__surprise",
+					Id:          surpriseItemId,
 					Title:       "🎉 Überrasch mich 🎉",
 					Description: "Die bestellende Person sucht sich etwas für dich aus 😎",
 					Variants: []*scraper.Variant{
 						{
-							Name:        "vegetarisch",
+							Name:        surpriseVariantVegetarian,
 							Description: "Vegetarisch",
 						},
 						{
-							Name:        "ohne zwiebeln",
+							Name:        surpriseVariantNoOnions,
 							Description: "one Zwiebeln (wenn vorhanden)",
 						},
 					},
@@ -91,18 +124,42 @@ func (t *Controller) GetScrapedData() (*scraper.Data, error) {
 	return data, nil
 }
 
-func (t *Controller) CreateOrderList(deadline *time.Time) (*model.OrderList, error) {
-	list := model.OrderList{
-		Id:       uuid.New().String(),
-		Created:  time.Now(),
-		Deadline: deadline,
+func (t *Controller) CreateOrderList(payload *model.CreateListPayload) (*model.OrderList, error) {
+	err := t.validator.Struct(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.createOrderList(&model.OrderList{
+		ListInfo:      payload.ListInfo,
+		Deadline:      payload.Deadline,
+		ManagementKey: uuid.New().String(),
+	})
+}
+
+func (t *Controller) createOrderList(list *model.OrderList) (*model.OrderList, error) {
+	if err := initOrderList(list); err != nil {
+		return nil, err
 	}
-	err := t.db.CreateOrderList(&list)
 
+	err := t.db.CreateOrderList(list)
 	if err != nil {
 		return nil, err
 	}
-	return &list, nil
+	return list, nil
+}
+
+// initOrderList checks the deadline of a new order list and sets the
+// fields assigned on creation.
+func initOrderList(list *model.OrderList) error {
+	if err := checkDeadline(list.Deadline); err != nil {
+		return err
+	}
+
+	list.Id = uuid.New().String()
+	list.Created = time.Now()
+	list.State = model.ListStateOpen
+	return nil
 }
 
 // Debug
@@ -114,137 +171,483 @@ func (c *Controller) GetOrderList(orderListId string) (*model.OrderList, error)
 	return c.db.GetOrderList(orderListId)
 }
 
-var ErrDeadlineExceeded = errors.New("deadline for this order list has been exceeded")
+// ListOrderLists returns a page of the order lists matching the filter
+// and the cursor of the next page, which is empty on the last page.
+func (t *Controller) ListOrderLists(filter *model.ListFilter) ([]*model.OrderListSummary, string, error) {
+	err := t.validator.Struct(filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// One more list than requested is fetched to find out whether there
+	// is a next page.
+	f := *filter
+	f.Limit++
+	lists, err := t.db.ListOrderLists(&f)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(lists) > filter.Limit {
+		lists = lists[:filter.Limit]
+		last := lists[len(lists)-1]
+		next = model.ListCursor{Value: last.CursorValue(filter.SortBy), Id: last.Id}.String()
+	}
+
+	return lists, next, nil
+}
 
 func (t *Controller) CreateOrder(orderListId string, order *model.Order) (*model.Order, error) {
 	list, err := t.db.GetOrderList(orderListId)
 	if err != nil {
 		return nil, err
 	}
-	if list.Deadline != nil && time.Now().After(*list.Deadline) {
-		return nil, ErrDeadlineExceeded
+	if err = checkListOpen(list, ""); err != nil {
+		return nil, err
 	}
 
 	order.Id = uuid.New().String()
 	order.Created = time.Now()
 	order.EditKey = uuid.New().String()
+	// Payments are only set by the manager via UpdatePayment.
+	order.Payment = model.Payment{}
 
-	err = t.validator.Struct(order)
+	data, err := t.GetScrapedData()
 	if err != nil {
 		return nil, err
 	}
-
-	for _, storeItem := range order.StoreItems {
-		item, ok, err := t.getStoreItem(storeItem.Id)
-		if err != nil {
-			return nil, err
-		}
-		if !ok {
-			return nil, elk.NewErrorf(ErrInvalidStoreItem, "invalid store item ID: %s", storeItem.Id)
-		}
-
-		var invalidVariants ListError
-		for _, variant := range storeItem.Variants {
-			if !item.VariantsContain(variant) {
-				invalidVariants = append(invalidVariants, variant)
-			}
-		}
-		if len(invalidVariants) > 0 {
-			return nil, elk.Wrap(ErrInvalidVariants, invalidVariants, "invalid variants")
-		}
-
-		var invalidDips ListError
-		for _, dip := range storeItem.Dips {
-			if !slices.Contains(item.Dips, dip) {
-				invalidDips = append(invalidDips, dip)
-			}
-		}
-		if len(invalidDips) > 0 {
-			return nil, elk.Wrap(ErrInvalidDips, invalidDips, "invalid dips")
-		}
+	if err = t.validateOrder(data, order); err != nil {
+		return nil, err
 	}
+	assignLineIds(order, nil)
+	calculateTotals(data, order)
 
 	err = t.db.CreateOrder(orderListId, order)
 	if err != nil {
 		return nil, err
 	}
+
+	t.events.Publish(orderListId, events.OrderCreated, order)
 	return order, nil
 }
 
 func (t *Controller) GetOrders(orderListId string) ([]*model.Order, error) {
-	orderList, err := t.db.GetOrderList(orderListId)
+	_, err := t.db.GetOrderList(orderListId)
 	if err != nil {
 		return nil, err
 	}
 
-	orderList.Orders, err = t.db.GetOrders(orderListId)
+	data, err := t.GetScrapedData()
 	if err != nil {
 		return nil, err
 	}
 
-	return t.db.GetOrders(orderListId)
+	return t.getOrders(orderListId, data)
 }
 
-func (t *Controller) DeleteOrderList(orderListId string) error {
-	err := t.db.DeleteOrderList(orderListId)
+// getOrders returns the orders of the list with their totals calculated
+// from the given menu.
+func (t *Controller) getOrders(orderListId string, data *scraper.Data) ([]*model.Order, error) {
+	orders, err := t.db.GetOrders(orderListId)
+	if err != nil {
+		return nil, err
+	}
+
+	calculateTotals(data, orders...)
+	return orders, nil
+}
+
+func (t *Controller) DeleteOrderList(orderListId, managementKey string) error {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return err
+	}
+	if err = checkManagementKey(list, managementKey); err != nil {
+		return err
+	}
+
+	err = t.db.DeleteOrderList(orderListId)
 	if err != nil {
 		return err
 	}
 
+	t.events.Publish(orderListId, events.ListDeleted, nil)
 	return nil
 }
 
+// UpdateOrderList changes the properties of the order list given in the
+// update. Only the manager of the list holding the management key may
+// do this. Connected clients are notified when the deadline changes.
+func (t *Controller) UpdateOrderList(orderListId, managementKey string, update *model.UpdateListPayload) (*model.OrderList, error) {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkManagementKey(list, managementKey); err != nil {
+		return nil, err
+	}
+
+	if !update.Deadline.Set {
+		return list, nil
+	}
+
+	if err = checkDeadline(update.Deadline.Value); err != nil {
+		return nil, err
+	}
+	list.Deadline = update.Deadline.Value
+
+	if err = t.db.UpdateOrderList(list); err != nil {
+		return nil, err
+	}
+
+	t.events.Publish(orderListId, events.DeadlineChanged, map[string]*time.Time{"deadline": list.Deadline})
+	return list, nil
+}
+
+// SetListState moves the order list into the given state. Only the
+// manager of the list holding the management key may do this, and only
+// along the transitions allowed by model.ListState.
+func (t *Controller) SetListState(orderListId, managementKey string, state model.ListState) (*model.OrderList, error) {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkManagementKey(list, managementKey); err != nil {
+		return nil, err
+	}
+
+	err = t.validator.Var(state, "required,oneof=open locked ordered delivered cancelled")
+	if err != nil {
+		return nil, err
+	}
+
+	if !list.State.CanTransitionTo(state) {
+		return nil, elk.NewErrorf(ErrInvalidState,
+			"order list can not be moved from state %s to %s", list.State, state)
+	}
+
+	if err = t.db.SetOrderListState(orderListId, state); err != nil {
+		return nil, err
+	}
+	list.State = state
+
+	t.events.Publish(orderListId, events.StateChanged, map[string]model.ListState{"state": state})
+
+	// Surprises are resolved once nobody can change their order anymore.
+	// The list is locked anyway if this fails, as the orderer can still
+	// resolve them on demand.
+	if state == model.ListStateLocked {
+		data, err := t.GetScrapedData()
+		if err == nil {
+			err = t.resolveSurprises(orderListId, data, nil)
+		}
+		if err != nil {
+			slog.Error("failed resolving surprises of locked list", "list", orderListId, "err", err)
+		}
+	}
+
+	return list, nil
+}
+
 func (t *Controller) GetOrder(orderListId, orderId string) (*model.Order, error) {
-	return t.db.GetOrder(orderListId, orderId)
+	order, err := t.db.GetOrder(orderListId, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := t.GetScrapedData()
+	if err != nil {
+		return nil, err
+	}
+
+	calculateTotals(data, order)
+	return order, nil
 }
 
 // UpdateOrder bearbeitet eine Bestellung nach der Prüfung des geheimen Schlüssels.
-func (t *Controller) UpdateOrder(orderListId, orderId, editKey string, updatedOrder *model.Order) (*model.Order, error) {
+// Anstelle des Schlüssels der Bestellung kann auch der Verwaltungsschlüssel der Liste
+// angegeben werden.
+func (t *Controller) UpdateOrder(orderListId, orderId, editKey, managementKey string, updatedOrder *model.Order) (*model.Order, error) {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return nil, err
+	}
 	order, err := t.db.GetOrder(orderListId, orderId)
 	if err != nil {
 		return nil, err
 	}
-	if order.EditKey != editKey {
-		return nil, errors.New("invalid edit key: access denied")
+	if err = checkEditKey(list, order, editKey, managementKey); err != nil {
+		return nil, err
+	}
+	if err = checkListOpen(list, managementKey); err != nil {
+		return nil, err
+	}
+
+	data, err := t.GetScrapedData()
+	if err != nil {
+		return nil, err
+	}
+	if err = t.validateOrder(data, updatedOrder); err != nil {
+		return nil, err
 	}
+	assignLineIds(updatedOrder, order)
+	keepSurprises(updatedOrder, order)
 
 	order.Creator = updatedOrder.Creator
 	order.StoreItems = updatedOrder.StoreItems
+	order.Drinks = updatedOrder.Drinks
 	order.Drink = updatedOrder.Drink
+	order.Note = updatedOrder.Note
+
+	calculateTotals(data, order)
 
-	if err := t.db.UpdateOrder(orderListId, order); err != nil {
+	if err = t.db.UpdateOrder(orderListId, order); err != nil {
 		return nil, err
 	}
+
+	t.events.Publish(orderListId, events.OrderUpdated, order)
 	return order, nil
 }
 
 // DeleteOrder löscht eine Bestellung nach der Prüfung von dem geheimen Schlüssel.
-func (t *Controller) DeleteOrder(orderListId, orderId, editKey string) error {
+// Anstelle des Schlüssels der Bestellung kann auch der Verwaltungsschlüssel der Liste
+// angegeben werden.
+func (t *Controller) DeleteOrder(orderListId, orderId, editKey, managementKey string) error {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return err
+	}
 	order, err := t.db.GetOrder(orderListId, orderId)
 	if err != nil {
 		return err
 	}
-	if order.EditKey != editKey {
-		return errors.New("invalid edit key: access denied")
+	if err = checkEditKey(list, order, editKey, managementKey); err != nil {
+		return err
+	}
+	if err = checkListOpen(list, managementKey); err != nil {
+		return err
 	}
-	return t.db.DeleteOrder(orderListId, orderId)
+
+	if err = t.db.DeleteOrder(orderListId, orderId); err != nil {
+		return err
+	}
+
+	t.events.Publish(orderListId, events.OrderDeleted, map[string]string{"id": orderId})
+	return nil
 }
 
-func (t *Controller) getStoreItem(id string) (si *scraper.StoreItem, ok bool, err error) {
-	data, err := t.GetScrapedData()
+// UpdatePayment sets the payment status of an order. Only the manager
+// of the order list holding the management key may do this. If an order
+// is marked as paid without an amount, the order total is assumed.
+func (t *Controller) UpdatePayment(orderListId, orderId, managementKey string, payment *model.Payment) (*model.Order, error) {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkManagementKey(list, managementKey); err != nil {
+		return nil, err
+	}
+
+	err = t.validator.Struct(payment)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := t.GetOrder(orderListId, orderId)
 	if err != nil {
-		return nil, false, err
+		return nil, err
+	}
+
+	if payment.Paid && payment.PaidCents == 0 {
+		payment.PaidCents = order.TotalCents
+	}
+
+	err = t.db.UpdatePayment(orderListId, orderId, payment)
+	if err != nil {
+		return nil, err
 	}
 
+	order.Payment = *payment
+
+	t.events.Publish(orderListId, events.OrderUpdated, order)
+	return order, nil
+}
+
+// SubscribeListEvents subscribes to the events of the given order list.
+// If lastEventId is not 0, events published after it which are still
+// available are returned as missed events.
+func (t *Controller) SubscribeListEvents(orderListId string, lastEventId uint64) (*events.Subscription, []*events.Event, error) {
+	_, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sub, missed := t.events.Subscribe(orderListId, lastEventId)
+	return sub, missed, nil
+}
+
+// checkManagementKey returns an ErrInvalidListKey error if the given key
+// does not match the management key of the list. Lists created before
+// management keys were introduced can not be managed at all.
+func checkManagementKey(list *model.OrderList, managementKey string) error {
+	if list.ManagementKey == "" || !keysEqual(list.ManagementKey, managementKey) {
+		return elk.NewError(ErrInvalidListKey, "invalid management key: access denied")
+	}
+	return nil
+}
+
+// validateOrder validates the order and checks that all of its store
+// items, variants, dips and drinks are on the menu. A drink given in the
+// deprecated drink field is taken as the only drink of the order, and
+// the names of the drinks are set to their names on the menu. Surprise
+// resolutions sent by clients are dropped.
+func (t *Controller) validateOrder(data *scraper.Data, order *model.Order) error {
+	order.SyncLegacyDrink()
+
+	// Older clients and orders placed before drinks had IDs only reference
+	// drinks by name.
+	for _, orderDrink := range order.Drinks {
+		if orderDrink == nil || orderDrink.Id != "" {
+			continue
+		}
+		if drink := findDrink(data, orderDrink); drink != nil {
+			orderDrink.Id = drink.Id
+		}
+	}
+
+	err := t.validator.Struct(order)
+	if err != nil {
+		return err
+	}
+
+	for _, storeItem := range order.StoreItems {
+		storeItem.Surprise = nil
+
+		item, ok := getStoreItem(data, storeItem.Id)
+		if !ok {
+			return elk.NewErrorf(ErrInvalidStoreItem, "invalid store item ID: %s", storeItem.Id)
+		}
+
+		var invalidVariants ListError
+		for _, variant := range storeItem.Variants {
+			if !item.VariantsContain(variant) {
+				invalidVariants = append(invalidVariants, variant)
+			}
+		}
+		if len(invalidVariants) > 0 {
+			return elk.Wrap(ErrInvalidVariants, invalidVariants, "invalid variants")
+		}
+
+		var invalidDips ListError
+		for _, dip := range storeItem.Dips {
+			if !slices.Contains(item.Dips, dip) {
+				invalidDips = append(invalidDips, dip)
+			}
+		}
+		if len(invalidDips) > 0 {
+			return elk.Wrap(ErrInvalidDips, invalidDips, "invalid dips")
+		}
+	}
+
+	for _, orderDrink := range order.Drinks {
+		drink := data.GetDrink(orderDrink.Id)
+		if drink == nil {
+			return elk.NewErrorf(ErrInvalidDrink, "invalid drink ID: %s", orderDrink.Id)
+		}
+		if int(orderDrink.Size) >= len(drink.Sizes) {
+			return elk.NewErrorf(ErrInvalidDrink,
+				"drink %s is not available in size %d", orderDrink.Id, orderDrink.Size)
+		}
+		orderDrink.Name = drink.Name
+		orderDrink.Quantity = max(orderDrink.Quantity, 1)
+	}
+
+	return nil
+}
+
+// assignLineIds sets the line id of each store item of the order. Line
+// ids of the previous version of the order are kept so that lines can be
+// referenced across updates; all other lines get a new id.
+func assignLineIds(order, previous *model.Order) {
+	known := make(map[string]bool)
+	if previous != nil {
+		for _, item := range previous.StoreItems {
+			known[item.LineId] = true
+		}
+	}
+
+	for _, item := range order.StoreItems {
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+		if item.LineId != "" && known[item.LineId] {
+			// Duplicated line ids are only kept for the first line.
+			delete(known, item.LineId)
+			continue
+		}
+		item.LineId = uuid.New().String()
+	}
+}
+
+// checkDeadline returns an ErrInvalidDeadline error if the given deadline
+// is not in the future. No deadline at all is valid.
+func checkDeadline(deadline *time.Time) error {
+	if deadline != nil && !deadline.After(time.Now()) {
+		return elk.NewError(ErrInvalidDeadline, "deadline must be in the future")
+	}
+	return nil
+}
+
+// checkListOpen returns an ErrListClosed error if the list is not open
+// and an ErrDeadlineExceeded error if its deadline has passed. The
+// manager of the list may still change orders after the deadline and
+// while the list is locked, for example to fix them before ordering.
+func checkListOpen(list *model.OrderList, managementKey string) error {
+	isManager := managementKey != "" && checkManagementKey(list, managementKey) == nil
+
+	switch {
+	case list.State == model.ListStateOpen:
+	case list.State == model.ListStateLocked && isManager:
+	default:
+		return elk.NewErrorf(ErrListClosed, "order list is %s", list.State)
+	}
+
+	if !isManager && list.Deadline != nil && time.Now().After(*list.Deadline) {
+		return elk.NewError(ErrDeadlineExceeded, "deadline for this order list has been exceeded")
+	}
+
+	return nil
+}
+
+// checkEditKey returns an ErrInvalidEditKey error if neither the edit key
+// matches the one of the order nor the management key matches the one
+// of the list.
+func checkEditKey(list *model.OrderList, order *model.Order, editKey, managementKey string) error {
+	if order.EditKey != "" && keysEqual(order.EditKey, editKey) {
+		return nil
+	}
+	if checkManagementKey(list, managementKey) == nil {
+		return nil
+	}
+	return elk.NewError(ErrInvalidEditKey, "invalid edit key: access denied")
+}
+
+// keysEqual compares two secret keys in constant time.
+func keysEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func getStoreItem(data *scraper.Data, id string) (si *scraper.StoreItem, ok bool) {
 	for _, category := range data.Categories {
 		for _, si = range category.Items {
 			if si.Id == id {
-				return si, true, nil
+				return si, true
 			}
 		}
 	}
 
-	return nil, false, nil
+	return nil, false
 }
 
 //Feedback\\
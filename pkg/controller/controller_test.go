@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/studio-b12/elk"
+	"github.com/zekrotja/hermans/pkg/model"
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+func TestValidateOrderNullLines(t *testing.T) {
+	ctl, err := New(t.TempDir(), nil, nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := &scraper.Data{
+		Categories: []*scraper.Category{
+			{Id: "burger", Name: "Burger", Items: []*scraper.StoreItem{{Id: "classic", Title: "Classic Burger"}}},
+		},
+		Drinks: []*scraper.Drink{{Id: "cola", Name: "Cola", Sizes: []*scraper.DrinkSize{{Name: "0,33 l"}}}},
+	}
+
+	tests := []string{
+		`{"creator": "Kim", "store_items": [null]}`,
+		`{"creator": "Kim", "store_items": [{"id": "classic"}], "drinks": [null]}`,
+		`{"creator": "Kim", "store_items": [{"id": "classic"}, null], "drinks": [{"id": "cola"}, null]}`,
+	}
+
+	for _, payload := range tests {
+		t.Run(payload, func(t *testing.T) {
+			var order model.Order
+			if err := json.Unmarshal([]byte(payload), &order); err != nil {
+				t.Fatal(err)
+			}
+			err := ctl.validateOrder(data, &order)
+			if _, ok := elk.As[validator.ValidationErrors](err); !ok {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
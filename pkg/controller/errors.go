@@ -10,7 +10,15 @@ const (
 	ErrInvalidStoreItem = elk.ErrorCode("controller:invalid-store-item")
 	ErrInvalidVariants  = elk.ErrorCode("controller:invalid-variants")
 	ErrInvalidDips      = elk.ErrorCode("controller:invalid-dips")
+	ErrInvalidDrink     = elk.ErrorCode("controller:invalid-drink")
 	ErrInvalidEditKey   = elk.ErrorCode("controller:invalid-edit-key")
+	ErrInvalidListKey   = elk.ErrorCode("controller:invalid-list-key")
+	ErrDeadlineExceeded = elk.ErrorCode("controller:deadline-exceeded")
+	ErrInvalidDeadline  = elk.ErrorCode("controller:invalid-deadline")
+	ErrListClosed       = elk.ErrorCode("controller:list-closed")
+	ErrInvalidState     = elk.ErrorCode("controller:invalid-state-transition")
+	ErrMenuUnavailable  = elk.ErrorCode("controller:menu-unavailable")
+	ErrInvalidSchedule  = elk.ErrorCode("controller:invalid-schedule")
 )
 
 type ListError []string
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+// GetListExport returns the order list with all of its orders prepared
+// for being rendered by the export package.
+func (t *Controller) GetListExport(orderListId string) (*model.ListExport, error) {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := t.GetScrapedData()
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := t.getOrders(orderListId, data)
+	if err != nil {
+		return nil, err
+	}
+	menu := indexMenu(data)
+
+	export := &model.ListExport{
+		List:   list,
+		Orders: make([]*model.ExportOrder, 0, len(orders)),
+	}
+	export.TotalCents, export.TotalIncomplete = model.SumTotals(orders)
+
+	for _, order := range orders {
+		exportOrder := &model.ExportOrder{
+			Creator:         order.Creator,
+			Lines:           make([]*model.ExportLine, 0, len(order.StoreItems)),
+			Note:            order.Note,
+			Paid:            order.Payment.Paid,
+			TotalCents:      order.TotalCents,
+			TotalIncomplete: order.TotalIncomplete,
+		}
+
+		for _, orderDrink := range order.Drinks {
+			drink := newSummaryDrink(*orderDrink, findDrink(data, orderDrink))
+			exportOrder.Drinks = append(exportOrder.Drinks, &model.ExportDrink{
+				Name:     drink.Name,
+				Size:     drink.SizeName,
+				Quantity: max(orderDrink.Quantity, 1),
+			})
+		}
+
+		for _, line := range order.StoreItems {
+			id, variants := line.Dish()
+			item := newSummaryItem(id, variants, line.Dips, menu)
+			exportLine := &model.ExportLine{
+				Title:       item.Title,
+				Quantity:    max(line.Quantity, 1),
+				Dips:        line.Dips,
+				Note:        line.Note,
+				Surprise:    line.Surprise != nil,
+				Unavailable: item.Unavailable,
+			}
+			if _, ok := menu[id]; !ok && line.Surprise != nil {
+				exportLine.Title = line.Surprise.Title
+			}
+			for _, variant := range item.Variants {
+				exportLine.Variants = append(exportLine.Variants, variant.Title)
+			}
+			exportOrder.Lines = append(exportOrder.Lines, exportLine)
+		}
+
+		export.Orders = append(export.Orders, exportOrder)
+	}
+
+	return export, nil
+}
@@ -1,20 +1,40 @@
 package controller
 
 import (
+	"context"
+	"time"
+
 	"github.com/zekrotja/hermans/pkg/model"
+	"github.com/zekrotja/hermans/pkg/scraper"
 )
 
 type Database interface {
 	CreateOrderList(list *model.OrderList) error
 	CreateOrder(orderListId string, order *model.Order) error
 	GetOrderList(orderListId string) (*model.OrderList, error)
+	ListOrderLists(filter *model.ListFilter) ([]*model.OrderListSummary, error)
+	UpdateOrderList(list *model.OrderList) error
+	SetOrderListState(orderListId string, state model.ListState) error
 	GetOrders(orderListId string) ([]*model.Order, error)
 	DeleteOrderList(orderListId string) error
 	GetOrder(orderListId, orderId string) (*model.Order, error)
 	UpdateOrder(orderListId string, order *model.Order) error
+	UpdateOrderLineSurprise(line *model.StoreItem, previous *model.Surprise) (bool, error)
+	UpdatePayment(orderListId, orderId string, payment *model.Payment) error
 	DeleteOrder(orderListId, orderId string) error
+	CreateSchedule(schedule *model.Schedule) error
+	GetSchedule(scheduleId string) (*model.Schedule, error)
+	GetSchedules() ([]*model.Schedule, error)
+	GetLatestOrderListOfSchedule(scheduleId string) (*model.OrderList, error)
+	CreateScheduledOrderList(list *model.OrderList, lastRun time.Time) error
+	DeleteSchedule(scheduleId string) error
 	ClearAllData() error //debug
 	//Feedback\\
 	CreateFeedback(feedback *model.Feedback) error
 	GetAllFeedback() ([]*model.Feedback, error)
 }
+
+// MenuSource provides the menu the orders are validated against.
+type MenuSource interface {
+	Fetch(ctx context.Context) (*scraper.Data, error)
+}
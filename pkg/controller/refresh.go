@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/brunoga/deep"
+	"github.com/studio-b12/elk"
+	"github.com/zekrotja/hermans/pkg/cache"
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+const (
+	// scrapeTimeout limits how long a scrape triggered by a request may
+	// take, so that requests do not hang while the web shop is slow.
+	scrapeTimeout = 30 * time.Second
+
+	scrapeBackoffMin = 10 * time.Second
+	scrapeBackoffMax = 10 * time.Minute
+)
+
+// RunScrapeRefresher re-scrapes the menu every interval until ctx is
+// done. When a scrape fails, the previously cached data is kept and
+// served until the next successful run.
+func (t *Controller) RunScrapeRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			slog.Debug("refreshing menu data ...")
+			if _, err := t.Scrape(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				slog.Error("failed refreshing menu data; keeping cached data", "err", err)
+				continue
+			}
+			t.scrapeBackoff.reset()
+			slog.Info("menu data refreshed")
+		}
+	}
+}
+
+// scrapeShared scrapes the menu with a timeout. Concurrent calls share a
+// single scrape, but each of them gets its own copy of the result, so
+// that callers can modify it. After failed scrapes, no new scrape is started until
+// the backoff has passed and ErrMenuUnavailable is returned instead.
+func (t *Controller) scrapeShared() (*scraper.Data, error) {
+	v, err, _ := t.scrapeGroup.Do("menu", func() (any, error) {
+		if wait := t.scrapeBackoff.remaining(); wait > 0 {
+			return nil, elk.NewErrorf(ErrMenuUnavailable,
+				"menu unavailable; next scrape attempt in %s", wait.Round(time.Second))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+		defer cancel()
+
+		data, err := t.Scrape(ctx)
+		if err != nil {
+			t.scrapeBackoff.fail()
+			return nil, err
+		}
+		t.scrapeBackoff.reset()
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := deep.Copy(v.(*scraper.Data))
+	if err != nil {
+		return nil, elk.Wrap(cache.ErrDeepCopy, err, "failed to deep copy menu data")
+	}
+	return data, nil
+}
+
+// refreshInBackground starts refreshing the expired cached menu unless a
+// refresh is already running or the backoff after failed scrapes has not
+// passed yet.
+func (t *Controller) refreshInBackground(storedAt time.Time) {
+	if t.scrapeBackoff.remaining() > 0 || !t.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer t.refreshing.Store(false)
+		if _, err := t.scrapeShared(); err != nil {
+			slog.Warn("failed refreshing expired menu data; serving stale data",
+				"err", err, "storedAt", storedAt)
+		}
+	}()
+}
+
+// backoff tracks consecutive failures and doubles the time to wait
+// before the next attempt with each of them, up to scrapeBackoffMax.
+type backoff struct {
+	mtx      sync.Mutex
+	failures int
+	retryAt  time.Time
+}
+
+func (t *backoff) remaining() time.Duration {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return time.Until(t.retryAt)
+}
+
+func (t *backoff) fail() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	wait := scrapeBackoffMax
+	if t.failures < 16 {
+		wait = min(scrapeBackoffMin<<t.failures, scrapeBackoffMax)
+	}
+	t.failures++
+	t.retryAt = time.Now().Add(wait)
+}
+
+func (t *backoff) reset() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.failures = 0
+	t.retryAt = time.Time{}
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+// slowMenu returns the same menu after a delay, so that concurrent
+// callers share a single fetch.
+type slowMenu struct {
+	data *scraper.Data
+}
+
+func (t *slowMenu) Fetch(ctx context.Context) (*scraper.Data, error) {
+	time.Sleep(50 * time.Millisecond)
+	return t.data, nil
+}
+
+func TestGetScrapedDataConcurrent(t *testing.T) {
+	menu := &slowMenu{data: &scraper.Data{Categories: []*scraper.Category{
+		{Id: "burger", Name: "Burger", Items: []*scraper.StoreItem{{Id: "classic", Title: "Classic Burger"}}},
+	}}}
+	ctl, err := New(t.TempDir(), nil, menu, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const callers = 4
+	results := make([]*scraper.Data, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = ctl.GetScrapedData()
+		}()
+	}
+	wg.Wait()
+
+	for i, data := range results {
+		if errs[i] != nil {
+			t.Fatalf("caller %d failed: %v", i, errs[i])
+		}
+		if len(data.Categories) != 2 || data.Categories[0].Id != surpriseCategoryId {
+			t.Errorf("caller %d: expected surprise category once, got %d categories", i, len(data.Categories))
+		}
+	}
+	if len(menu.data.Categories) != 1 {
+		t.Errorf("fetched menu has been modified: %d categories", len(menu.data.Categories))
+	}
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"github.com/studio-b12/elk"
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+// CreateSchedule creates a new schedule from which order lists are
+// created periodically. The returned schedule contains the management
+// key which is also used to manage the lists created from it.
+func (t *Controller) CreateSchedule(schedule *model.Schedule) (*model.Schedule, error) {
+	err := t.validator.Struct(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	sched, err := parseCron(schedule.Cron)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Id = uuid.New().String()
+	schedule.Created = time.Now()
+	schedule.LastRun = nil
+	schedule.ManagementKey = uuid.New().String()
+
+	if err = t.db.CreateSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	setNextRun(schedule, sched)
+	return schedule, nil
+}
+
+func (t *Controller) GetSchedule(scheduleId string) (*model.Schedule, error) {
+	schedule, err := t.db.GetSchedule(scheduleId)
+	if err != nil {
+		return nil, err
+	}
+
+	if sched, err := parseCron(schedule.Cron); err == nil {
+		setNextRun(schedule, sched)
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule stops creating lists from the schedule. Lists which have
+// already been created are kept.
+func (t *Controller) DeleteSchedule(scheduleId, managementKey string) error {
+	schedule, err := t.db.GetSchedule(scheduleId)
+	if err != nil {
+		return err
+	}
+	if !keysEqual(schedule.ManagementKey, managementKey) {
+		return elk.NewError(ErrInvalidListKey, "invalid management key: access denied")
+	}
+
+	return t.db.DeleteSchedule(scheduleId)
+}
+
+// GetCurrentScheduleList returns the latest order list created from the
+// given schedule.
+func (t *Controller) GetCurrentScheduleList(scheduleId string) (*model.OrderList, error) {
+	_, err := t.db.GetSchedule(scheduleId)
+	if err != nil {
+		return nil, err
+	}
+	return t.db.GetLatestOrderListOfSchedule(scheduleId)
+}
+
+// RunScheduler checks every interval whether a schedule is due and
+// creates a new order list for it until ctx is done. Runs missed while
+// the scheduler was not running are caught up with a single list.
+func (t *Controller) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		t.runSchedules(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *Controller) runSchedules(now time.Time) {
+	schedules, err := t.db.GetSchedules()
+	if err != nil {
+		slog.Error("failed loading schedules", "err", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		sched, err := parseCron(schedule.Cron)
+		if err != nil {
+			slog.Error("invalid schedule", "schedule", schedule.Id, "err", err)
+			continue
+		}
+
+		last := schedule.Created
+		if schedule.LastRun != nil {
+			last = *schedule.LastRun
+		}
+		if sched.Next(last).After(now) {
+			continue
+		}
+
+		list := &model.OrderList{
+			ListInfo:      model.ListInfo{Title: schedule.Title},
+			ScheduleId:    schedule.Id,
+			ManagementKey: schedule.ManagementKey,
+		}
+		if schedule.DeadlineOffsetMinutes > 0 {
+			deadline := now.Add(schedule.DeadlineOffset())
+			list.Deadline = &deadline
+		}
+
+		// The list is created together with recording the run, so that a
+		// failure does not create another list on the next check.
+		if err = initOrderList(list); err == nil {
+			err = t.db.CreateScheduledOrderList(list, now)
+		}
+		if err != nil {
+			slog.Error("failed creating scheduled order list", "schedule", schedule.Id, "err", err)
+			continue
+		}
+
+		slog.Info("created scheduled order list", "schedule", schedule.Id, "list", list.Id)
+	}
+}
+
+func parseCron(spec string) (cron.Schedule, error) {
+	sched, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, elk.Wrap(ErrInvalidSchedule, err, "invalid cron expression")
+	}
+	return sched, nil
+}
+
+func setNextRun(schedule *model.Schedule, sched cron.Schedule) {
+	last := schedule.Created
+	if schedule.LastRun != nil {
+		last = *schedule.LastRun
+	}
+	next := sched.Next(last)
+	schedule.NextRun = &next
+}
@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/zekrotja/hermans/pkg/model"
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+// GetListSummary consolidates all orders of the list into the order to be
+// placed at the shop. Store item IDs and variant names are resolved to
+// their titles on the current menu.
+func (t *Controller) GetListSummary(orderListId string) (*model.ListSummary, error) {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := t.GetScrapedData()
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := t.getOrders(orderListId, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarize(list, orders, data), nil
+}
+
+type menuEntry struct {
+	item     *scraper.StoreItem
+	category string
+	position int
+}
+
+// indexMenu maps the IDs of all store items on the menu to their entry.
+func indexMenu(data *scraper.Data) map[string]menuEntry {
+	menu := make(map[string]menuEntry)
+	for _, cat := range data.Categories {
+		for _, item := range cat.Items {
+			menu[item.Id] = menuEntry{item: item, category: cat.Name, position: len(menu)}
+		}
+	}
+	return menu
+}
+
+func summarize(list *model.OrderList, orders []*model.Order, data *scraper.Data) *model.ListSummary {
+	menu := indexMenu(data)
+
+	summary := &model.ListSummary{
+		Id:         list.Id,
+		Title:      list.Title,
+		OrderCount: len(orders),
+		Items:      []*model.SummaryItem{},
+		Drinks:     []*model.SummaryDrink{},
+	}
+	summary.TotalCents, summary.TotalIncomplete = model.SumTotals(orders)
+
+	items := make(map[string]*model.SummaryItem)
+	positions := make(map[*model.SummaryItem]int)
+	drinkLines := make(map[model.Drink]*model.SummaryDrink)
+
+	for _, order := range orders {
+		for _, line := range order.StoreItems {
+			// Resolved surprises are counted as the dish they were
+			// resolved to, as this is what is ordered at the shop.
+			id, variants := line.Dish()
+			variants = slices.Sorted(slices.Values(variants))
+			dips := slices.Sorted(slices.Values(line.Dips))
+			key := id + "\x00" + strings.Join(variants, "\x00") + "\x01" + strings.Join(dips, "\x00")
+
+			item, ok := items[key]
+			if !ok {
+				item = newSummaryItem(id, variants, dips, menu)
+				if _, ok := menu[id]; !ok && line.Surprise != nil {
+					item.Title = line.Surprise.Title
+				}
+				items[key] = item
+				summary.Items = append(summary.Items, item)
+
+				positions[item] = len(menu)
+				if entry, ok := menu[id]; ok {
+					positions[item] = entry.position
+				}
+			}
+
+			item.Count += max(line.Quantity, 1)
+			if line.Surprise != nil {
+				item.Surprises += max(line.Quantity, 1)
+			}
+			item.Creators = appendUnique(item.Creators, order.Creator)
+			if line.Note != "" {
+				item.Notes = append(item.Notes, &model.SummaryNote{Creator: order.Creator, Note: line.Note})
+			}
+		}
+
+		for _, orderDrink := range order.Drinks {
+			// Drinks are grouped by the drink on the menu they reference
+			// so that orders placed before drinks had IDs are counted
+			// together with newer ones.
+			key := model.Drink{Id: orderDrink.Id, Name: orderDrink.Name, Size: orderDrink.Size}
+			menuDrink := findDrink(data, orderDrink)
+			if menuDrink != nil {
+				key = model.Drink{Id: menuDrink.Id, Name: menuDrink.Name, Size: orderDrink.Size}
+			}
+
+			drink, ok := drinkLines[key]
+			if !ok {
+				drink = newSummaryDrink(key, menuDrink)
+				drinkLines[key] = drink
+				summary.Drinks = append(summary.Drinks, drink)
+			}
+			drink.Count += max(orderDrink.Quantity, 1)
+			drink.Creators = appendUnique(drink.Creators, order.Creator)
+		}
+
+		if order.Note != "" {
+			summary.Notes = append(summary.Notes, &model.SummaryNote{Creator: order.Creator, Note: order.Note})
+		}
+	}
+
+	slices.SortStableFunc(summary.Items, func(a, b *model.SummaryItem) int {
+		return cmp.Or(
+			cmp.Compare(positions[a], positions[b]),
+			strings.Compare(a.StoreItemId, b.StoreItemId),
+			cmp.Compare(len(a.Variants)+len(a.Dips), len(b.Variants)+len(b.Dips)))
+	})
+	slices.SortStableFunc(summary.Drinks, func(a, b *model.SummaryDrink) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.Size, b.Size))
+	})
+
+	for _, item := range summary.Items {
+		summary.HasUnavailable = summary.HasUnavailable || item.Unavailable
+	}
+	for _, drink := range summary.Drinks {
+		summary.HasUnavailable = summary.HasUnavailable || drink.Unavailable
+	}
+
+	return summary
+}
+
+func newSummaryItem(id string, variants, dips []string, menu map[string]menuEntry) *model.SummaryItem {
+	item := &model.SummaryItem{
+		StoreItemId: id,
+		Title:       id,
+		Dips:        dips,
+	}
+
+	entry, ok := menu[id]
+	if !ok {
+		item.Unavailable = true
+	} else {
+		item.Title = entry.item.Title
+		item.Category = entry.category
+	}
+
+	for _, name := range variants {
+		variant := &model.SummaryVariant{Name: name, Title: name}
+		if ok {
+			if v := entry.item.GetVariant(name); v == nil {
+				item.Unavailable = true
+			} else if v.Description != "" {
+				variant.Title = v.Description
+			}
+		}
+		item.Variants = append(item.Variants, variant)
+	}
+
+	if ok {
+		for _, dip := range dips {
+			if !slices.Contains(entry.item.Dips, dip) {
+				item.Unavailable = true
+			}
+		}
+	}
+
+	return item
+}
+
+func newSummaryDrink(drink model.Drink, menuDrink *scraper.Drink) *model.SummaryDrink {
+	summaryDrink := &model.SummaryDrink{
+		Id:          drink.Id,
+		Name:        drink.Name,
+		Size:        drink.Size,
+		Unavailable: true,
+	}
+	if menuDrink != nil && int(drink.Size) < len(menuDrink.Sizes) {
+		summaryDrink.SizeName = menuDrink.Sizes[drink.Size].Name
+		summaryDrink.Unavailable = false
+	}
+	return summaryDrink
+}
+
+func appendUnique(s []string, v string) []string {
+	if slices.Contains(s, v) {
+		return s
+	}
+	return append(s, v)
+}
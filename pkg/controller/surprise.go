@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+
+	"github.com/zekrotja/hermans/pkg/events"
+	"github.com/zekrotja/hermans/pkg/model"
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+// The surprise item is a synthetic store item added to the menu by
+// GetScrapedData. Its lines are resolved to a dish of the menu by the
+// orderer.
+const (
+	surpriseCategoryId = "__etc"
+	surpriseItemId     = "__surprise"
+
+	surpriseVariantVegetarian = "vegetarisch"
+	surpriseVariantNoOnions   = "ohne zwiebeln"
+)
+
+var (
+	// The menu does not mark vegetarian dishes, so they are recognized
+	// by their category, title or variants. Descriptions are not trusted,
+	// as they also mention vegetarian options of meat dishes.
+	vegetarianRx = regexp.MustCompile(`(?i)vegetar|vegan|veggie`)
+	noOnionsRx   = regexp.MustCompile(`(?i)ohne[\s_-]*zwiebel`)
+)
+
+// ResolveSurprises picks a dish for the surprise lines of all orders of
+// the list and returns the orders. Without a seed, only lines which are
+// not resolved yet are resolved using a seed derived from the list ID,
+// so that the result does not change when resolving again. With a seed,
+// all surprise lines are resolved again, which lets the orderer reroll.
+// A line is resolved to a single dish, so all units of a line with a
+// quantity above 1 get the same dish. Only the surprise of each line is
+// stored, so that concurrent changes of the orders are kept.
+func (t *Controller) ResolveSurprises(orderListId, managementKey string, seed *uint64) ([]*model.Order, error) {
+	list, err := t.db.GetOrderList(orderListId)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkManagementKey(list, managementKey); err != nil {
+		return nil, err
+	}
+	if err = checkListOpen(list, managementKey); err != nil {
+		return nil, err
+	}
+
+	data, err := t.GetScrapedData()
+	if err != nil {
+		return nil, err
+	}
+	if err = t.resolveSurprises(orderListId, data, seed); err != nil {
+		return nil, err
+	}
+
+	return t.getOrders(orderListId, data)
+}
+
+func (t *Controller) resolveSurprises(orderListId string, data *scraper.Data, seed *uint64) error {
+	orders, err := t.db.GetOrders(orderListId)
+	if err != nil {
+		return err
+	}
+
+	s := hashString(orderListId)
+	if seed != nil {
+		s = *seed
+	}
+
+	for _, order := range orders {
+		changed := false
+		for _, line := range order.StoreItems {
+			if line.Id != surpriseItemId || (line.Surprise != nil && seed == nil) {
+				continue
+			}
+			rng := rand.New(rand.NewPCG(s, hashString(line.LineId)))
+			surprise := pickSurprise(data, line.Variants, rng)
+			if surprise == nil {
+				slog.Warn("no dish on the menu matches surprise line",
+					"list", orderListId, "line", line.LineId, "variants", line.Variants)
+				continue
+			}
+			previous := line.Surprise
+			line.Surprise = surprise
+			updated, err := t.db.UpdateOrderLineSurprise(line, previous)
+			if err != nil {
+				return err
+			}
+			// Lines changed in the meantime are resolved next time.
+			changed = changed || updated
+		}
+		if !changed {
+			continue
+		}
+
+		// The order is read again as it may have been changed apart from
+		// the resolved lines.
+		order, err = t.db.GetOrder(orderListId, order.Id)
+		if err != nil {
+			return err
+		}
+		calculateTotals(data, order)
+		t.events.Publish(orderListId, events.OrderUpdated, order)
+	}
+
+	return nil
+}
+
+// pickSurprise picks a random dish of the menu honoring the given
+// variants of a surprise line. Vegetarian lines only get dishes whose
+// category or title is vegetarian, or which have a vegetarian variant,
+// which is then selected. The variant for leaving out onions is selected
+// if the dish has one. nil is returned if no dish matches.
+func pickSurprise(data *scraper.Data, variants []string, rng *rand.Rand) *model.Surprise {
+	vegetarian := slices.Contains(variants, surpriseVariantVegetarian)
+	noOnions := slices.Contains(variants, surpriseVariantNoOnions)
+
+	var candidates []*model.Surprise
+	for _, category := range data.Categories {
+		if category.Id == surpriseCategoryId {
+			continue
+		}
+		for _, item := range category.Items {
+			surprise := &model.Surprise{Id: item.Id, Title: item.Title}
+			if vegetarian && !vegetarianRx.MatchString(category.Name+" "+item.Title) {
+				variant := findVariant(item, vegetarianRx)
+				if variant == nil {
+					continue
+				}
+				surprise.Variants = append(surprise.Variants, variant.Name)
+			}
+			if noOnions {
+				if variant := findVariant(item, noOnionsRx); variant != nil {
+					surprise.Variants = append(surprise.Variants, variant.Name)
+				}
+			}
+			candidates = append(candidates, surprise)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rng.IntN(len(candidates))]
+}
+
+func findVariant(item *scraper.StoreItem, rx *regexp.Regexp) *scraper.Variant {
+	for _, variant := range item.Variants {
+		if rx.MatchString(variant.Name) || rx.MatchString(variant.Description) {
+			return variant
+		}
+	}
+	return nil
+}
+
+// keepSurprises keeps the resolutions of the surprise lines of the
+// previous version of the order for lines which still order the surprise
+// item with the same variants.
+func keepSurprises(order, previous *model.Order) {
+	resolved := make(map[string]*model.StoreItem)
+	for _, line := range previous.StoreItems {
+		if line.Surprise != nil {
+			resolved[line.LineId] = line
+		}
+	}
+
+	for _, line := range order.StoreItems {
+		prev, ok := resolved[line.LineId]
+		if ok && line.Id == surpriseItemId && slices.Equal(slices.Sorted(slices.Values(line.Variants)),
+			slices.Sorted(slices.Values(prev.Variants))) {
+			line.Surprise = prev.Surprise
+		}
+	}
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/zekrotja/hermans/pkg/model"
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+func TestPickSurpriseVegetarian(t *testing.T) {
+	data := &scraper.Data{Categories: []*scraper.Category{
+		{Id: "burger", Name: "Burger", Items: []*scraper.StoreItem{
+			{Id: "classic", Title: "Classic Burger", Description: "Rindfleisch, auch vegetarisch erhältlich"},
+			{Id: "chicken", Title: "Chicken Burger", Description: "auch vegetarisch erhältlich", Variants: []*scraper.Variant{
+				{Name: "ohne_zwiebeln", Description: "ohne Zwiebeln"},
+				{Name: "veggie", Description: "mit vegetarischem Patty"},
+			}},
+			{Id: "halloumi", Title: "Veggie Burger", Variants: []*scraper.Variant{
+				{Name: "ohne_zwiebeln", Description: "ohne Zwiebeln"},
+			}},
+		}},
+		{Id: "salate", Name: "Vegetarische Salate", Items: []*scraper.StoreItem{
+			{Id: "caesar", Title: "Caesar Salad"},
+		}},
+		{Id: surpriseCategoryId, Name: "Sonstiges", Items: []*scraper.StoreItem{
+			{Id: surpriseItemId, Title: "Überraschung (vegetarisch)"},
+		}},
+	}}
+
+	want := map[string]model.Surprise{
+		"chicken":  {Id: "chicken", Title: "Chicken Burger", Variants: []string{"veggie", "ohne_zwiebeln"}},
+		"halloumi": {Id: "halloumi", Title: "Veggie Burger", Variants: []string{"ohne_zwiebeln"}},
+		"caesar":   {Id: "caesar", Title: "Caesar Salad"},
+	}
+
+	picked := make(map[string]bool)
+	for seed := range uint64(100) {
+		rng := rand.New(rand.NewPCG(seed, 0))
+		surprise := pickSurprise(data, []string{surpriseVariantVegetarian, surpriseVariantNoOnions}, rng)
+		if surprise == nil {
+			t.Fatal("expected a surprise to be picked")
+		}
+		expected, ok := want[surprise.Id]
+		if !ok {
+			t.Fatalf("picked %s which is not vegetarian", surprise.Id)
+		}
+		if surprise.Title != expected.Title || !slices.Equal(surprise.Variants, expected.Variants) {
+			t.Errorf("expected %+v, got %+v", expected, *surprise)
+		}
+		picked[surprise.Id] = true
+	}
+	if len(picked) != len(want) {
+		t.Errorf("expected all vegetarian dishes to be picked, got %v", picked)
+	}
+}
+
+func TestPickSurpriseNoMatch(t *testing.T) {
+	data := &scraper.Data{Categories: []*scraper.Category{
+		{Id: "burger", Name: "Burger", Items: []*scraper.StoreItem{
+			{Id: "classic", Title: "Classic Burger", Description: "auch vegetarisch erhältlich"},
+		}},
+	}}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	if surprise := pickSurprise(data, []string{surpriseVariantVegetarian}, rng); surprise != nil {
+		t.Errorf("expected no surprise, got %+v", *surprise)
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"github.com/zekrotja/hermans/pkg/model"
+	"github.com/zekrotja/hermans/pkg/scraper"
+)
+
+// calculateTotals sets the total price of each given order based on the
+// prices of the current menu. Variant and dip surcharges are added where
+// the menu states them. Resolved surprise lines are priced as the dish
+// they were resolved to. If the price of any item or drink of an order is
+// unknown, the order total is marked as incomplete.
+func calculateTotals(data *scraper.Data, orders ...*model.Order) {
+	items := make(map[string]*scraper.StoreItem)
+	for _, cat := range data.Categories {
+		for _, item := range cat.Items {
+			items[item.Id] = item
+		}
+	}
+
+	for _, order := range orders {
+		order.TotalCents = 0
+		order.TotalIncomplete = false
+
+		for _, storeItem := range order.StoreItems {
+			id, variants := storeItem.Dish()
+			item, ok := items[id]
+			if !ok || item.PriceCents == nil {
+				order.TotalIncomplete = true
+				continue
+			}
+
+			lineCents := *item.PriceCents
+			for _, name := range variants {
+				if variant := item.GetVariant(name); variant != nil {
+					lineCents += variant.SurchargeCents
+				}
+			}
+			for _, dip := range storeItem.Dips {
+				lineCents += scraper.ParseSurcharge(dip)
+			}
+			order.TotalCents += lineCents * max(storeItem.Quantity, 1)
+		}
+
+		for _, orderDrink := range order.Drinks {
+			drink := findDrink(data, orderDrink)
+			if drink == nil {
+				order.TotalIncomplete = true
+				continue
+			}
+			price, ok := drink.PriceForSize(int(orderDrink.Size))
+			if !ok {
+				order.TotalIncomplete = true
+				continue
+			}
+			order.TotalCents += price * max(orderDrink.Quantity, 1)
+		}
+	}
+}
+
+// findDrink returns the drink on the menu the ordered drink references
+// or nil if there is none. Drinks of orders placed before drinks had IDs
+// are looked up by their name.
+func findDrink(data *scraper.Data, drink *model.Drink) *scraper.Drink {
+	if drink.Id != "" {
+		return data.GetDrink(drink.Id)
+	}
+	for _, d := range data.Drinks {
+		if d.Name == drink.Name {
+			return d
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"embed"
 	"strings"
+	"time"
 
 	_ "github.com/glebarez/go-sqlite"
 	"github.com/google/uuid"
@@ -36,10 +37,17 @@ func New(file string) (*Database, error) {
 	return &Database{conn: conn}, nil
 }
 
+const insertOrderList = `INSERT INTO "OrderList" ("Id", "Created", "Title", "Description", "Organizer",
+	"PickupTime", "PickupLocation", "Deadline", "State", "ScheduleId", "ManagementKey")
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+
+func orderListValues(list *model.OrderList) []any {
+	return []any{list.Id, list.Created.UTC(), list.Title, list.Description, list.Organizer, nullTime(list.PickupTime),
+		list.PickupLocation, nullTime(list.Deadline), list.State, nullString(list.ScheduleId), list.ManagementKey}
+}
+
 func (t *Database) CreateOrderList(list *model.OrderList) error {
-	_, err := t.conn.Exec(
-		`INSERT INTO "OrderList" ("Id", "Created", "Deadline") VALUES (?, ?, ?);`,
-		list.Id, list.Created, list.Deadline)
+	_, err := t.conn.Exec(insertOrderList, orderListValues(list)...)
 	return wrapErr(err)
 }
 
@@ -50,161 +58,222 @@ func (t *Database) CreateOrder(orderListId string, order *model.Order) error {
 	}
 	defer tx.Rollback()
 
-	var drinkId sql.NullString
-	if order.Drink != nil {
-		drinkId.String = uuid.New().String()
-		drinkId.Valid = true
-		_, err = tx.Exec(
-			`INSERT INTO "Drink" ("Id", "Name", "Size") VALUES (?, ?, ?);`,
-			drinkId.String, order.Drink.Name, order.Drink.Size)
-		if err != nil {
-			return wrapErr(err)
-		}
-	}
-
 	_, err = tx.Exec(
-		`INSERT INTO "Order" ("Id", "Created", "Creator", "OrderListId", "DrinkId", "EditKey")
+		`INSERT INTO "Order" ("Id", "Created", "Creator", "OrderListId", "Note", "EditKey")
 		 VALUES (?, ?, ?, ?, ?, ?);`,
-		order.Id, order.Created, order.Creator, orderListId, drinkId, order.EditKey)
+		order.Id, order.Created.UTC(), order.Creator, orderListId, order.Note, order.EditKey)
 	if err != nil {
 		return wrapErr(err)
 	}
 
-	for _, item := range order.StoreItems {
-		_, err = tx.Exec(
-			`INSERT INTO "OrderItems" ("OrderId", "StoreItemId") VALUES (?, ?);`,
-			order.Id, item.Id)
-		if err != nil {
-			return wrapErr(err)
-		}
-		for _, variant := range item.Variants {
-			_, err = tx.Exec(
-				`INSERT INTO "StoreItemVariant" ("OrderId", "StoreItemId", "Variant") VALUES (?, ?, ?);`,
-				order.Id, item.Id, variant)
-			if err != nil {
-				return wrapErr(err)
-			}
-		}
-		for _, dip := range item.Dips {
-			_, err = tx.Exec(
-				`INSERT INTO "StoreItemDip" ("OrderId", "StoreItemId", "Dip") VALUES (?, ?, ?);`,
-				order.Id, item.Id, dip)
-			if err != nil {
-				return wrapErr(err)
-			}
-		}
+	if err = insertOrderLines(tx, order); err != nil {
+		return err
+	}
+	if err = insertOrderDrinks(tx, order); err != nil {
+		return err
 	}
 
 	return wrapErr(tx.Commit())
 }
 
 func (t *Database) GetOrderList(orderListId string) (*model.OrderList, error) {
+	row := t.conn.QueryRow(
+		`SELECT `+orderListColumns+` FROM "OrderList" WHERE "Id" = ?`,
+		orderListId)
+	return scanOrderList(row)
+}
+
+// GetLatestOrderListOfSchedule returns the most recently created order
+// list of the given schedule.
+func (t *Database) GetLatestOrderListOfSchedule(scheduleId string) (*model.OrderList, error) {
+	row := t.conn.QueryRow(
+		`SELECT `+orderListColumns+` FROM "OrderList" WHERE "ScheduleId" = ? ORDER BY "Created" DESC LIMIT 1`,
+		scheduleId)
+	return scanOrderList(row)
+}
+
+const orderListColumns = `"Id", "Created", "Title", "Description", "Organizer", "PickupTime", "PickupLocation",
+	"Deadline", "State", "ScheduleId", "ManagementKey"`
+
+func scanOrderList(row scanner) (*model.OrderList, error) {
 	var list model.OrderList
-	var deadline sql.NullTime
-	err := t.conn.QueryRow(`SELECT "Id", "Created", "Deadline" FROM "OrderList" WHERE "Id" = ?`, orderListId).
-		Scan(&list.Id, &list.Created, &deadline)
+	var pickupTime, deadline sql.NullTime
+	var scheduleId, managementKey sql.NullString
+	err := row.Scan(&list.Id, &list.Created, &list.Title, &list.Description, &list.Organizer, &pickupTime,
+		&list.PickupLocation, &deadline, &list.State, &scheduleId, &managementKey)
 	if err != nil {
 		return nil, wrapErr(err)
 	}
+	if pickupTime.Valid {
+		list.PickupTime = &pickupTime.Time
+	}
 	if deadline.Valid {
 		list.Deadline = &deadline.Time
 	}
+	list.ScheduleId = scheduleId.String
+	list.ManagementKey = managementKey.String
 	return &list, nil
 }
 
-func (t *Database) GetOrders(orderListId string) ([]*model.Order, error) {
-	rows, err := t.conn.Query(`
-        SELECT o.Id, o.Created, o.Creator, o.EditKey, d.Name, d.Size 
-        FROM "Order" o 
-        LEFT JOIN "Drink" d ON d.Id = o.DrinkId 
-        WHERE o.OrderListId = ?`, orderListId)
+// ListOrderLists returns the order lists matching the given filter. At
+// most filter.Limit lists are returned, starting after filter.After.
+func (t *Database) ListOrderLists(filter *model.ListFilter) ([]*model.OrderListSummary, error) {
+	var (
+		where []string
+		args  []any
+	)
+
+	now := time.Now().UTC()
+	switch filter.Status {
+	case model.ListStatusOpen:
+		where = append(where, `l."State" = ? AND (l."Deadline" IS NULL OR l."Deadline" > ?)`)
+		args = append(args, model.ListStateOpen, now)
+	case model.ListStatusClosed:
+		where = append(where, `(l."State" != ? OR l."Deadline" <= ?)`)
+		args = append(args, model.ListStateOpen, now)
+	}
+	if filter.CreatedAfter != nil {
+		where = append(where, `l."Created" >= ?`)
+		args = append(args, filter.CreatedAfter.UTC())
+	}
+	if filter.CreatedBefore != nil {
+		where = append(where, `l."Created" < ?`)
+		args = append(args, filter.CreatedBefore.UTC())
+	}
+	if filter.HasDeadline != nil {
+		if *filter.HasDeadline {
+			where = append(where, `l."Deadline" IS NOT NULL`)
+		} else {
+			where = append(where, `l."Deadline" IS NULL`)
+		}
+	}
+
+	sortExpr := `l."Created"`
+	if filter.SortBy == model.ListSortDeadline {
+		sortExpr = `COALESCE(l."Deadline", ?)`
+	}
+	sortArgs := func() {
+		if filter.SortBy == model.ListSortDeadline {
+			args = append(args, model.NoDeadline)
+		}
+	}
+
+	cmp, order := ">", "ASC"
+	if filter.Descending {
+		cmp, order = "<", "DESC"
+	}
+	if filter.After != nil {
+		where = append(where, `(`+sortExpr+` `+cmp+` ? OR (`+sortExpr+` = ? AND l."Id" `+cmp+` ?))`)
+		sortArgs()
+		args = append(args, filter.After.Value.UTC())
+		sortArgs()
+		args = append(args, filter.After.Value.UTC(), filter.After.Id)
+	}
+
+	query := `SELECT l."Id", l."Created", l."Title", l."Description", l."Organizer", l."PickupTime", l."PickupLocation",
+		l."Deadline", l."State", l."ScheduleId",
+		(SELECT COUNT(*) FROM "Order" o WHERE o."OrderListId" = l."Id")
+		FROM "OrderList" l`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY ` + sortExpr + ` ` + order + `, l."Id" ` + order + ` LIMIT ?`
+	sortArgs()
+	args = append(args, filter.Limit)
+
+	rows, err := t.conn.Query(query, args...)
 	if err != nil {
 		return nil, wrapErr(err)
 	}
 	defer rows.Close()
 
-	ordersMap := make(map[string]*model.Order)
-	var orderIDs []interface{}
-
+	lists := []*model.OrderListSummary{}
 	for rows.Next() {
-		var order model.Order
-		var drinkName sql.NullString
-		var drinkSize sql.NullInt64
-		if err := rows.Scan(&order.Id, &order.Created, &order.Creator, &order.EditKey, &drinkName, &drinkSize); err != nil {
+		var list model.OrderListSummary
+		var pickupTime, deadline sql.NullTime
+		var scheduleId sql.NullString
+		err = rows.Scan(&list.Id, &list.Created, &list.Title, &list.Description, &list.Organizer, &pickupTime,
+			&list.PickupLocation, &deadline, &list.State, &scheduleId, &list.OrderCount)
+		if err != nil {
 			return nil, wrapErr(err)
 		}
-		if drinkName.Valid {
-			order.Drink = &model.Drink{Name: drinkName.String, Size: model.DrinkSize(drinkSize.Int64)}
+		if pickupTime.Valid {
+			list.PickupTime = &pickupTime.Time
 		}
-		order.StoreItems = []*model.StoreItem{}
-		ordersMap[order.Id] = &order
-		orderIDs = append(orderIDs, order.Id)
-	}
-	if err = rows.Err(); err != nil {
-		return nil, wrapErr(err)
+		if deadline.Valid {
+			list.Deadline = &deadline.Time
+		}
+		list.ScheduleId = scheduleId.String
+		lists = append(lists, &list)
 	}
-	if len(orderIDs) == 0 {
-		return []*model.Order{}, nil
+	return lists, wrapErr(rows.Err())
+}
+
+func (t *Database) UpdateOrderList(list *model.OrderList) error {
+	res, err := t.conn.Exec(`UPDATE "OrderList" SET "Deadline" = ? WHERE "Id" = ?`, nullTime(list.Deadline), list.Id)
+	if err != nil {
+		return wrapErr(err)
 	}
+	return wrapErr(checkAffected(res))
+}
 
-	query := `
-        SELECT oi.OrderId, oi.StoreItemId, 
-               GROUP_CONCAT(DISTINCT sv.Variant) as variants, 
-               GROUP_CONCAT(DISTINCT sd.Dip) as dips
-        FROM OrderItems oi
-        LEFT JOIN StoreItemVariant sv ON sv.OrderId = oi.OrderId AND sv.StoreItemId = oi.StoreItemId
-        LEFT JOIN StoreItemDip sd ON sd.OrderId = oi.OrderId AND sd.StoreItemId = oi.StoreItemId
-        WHERE oi.OrderId IN (?` + strings.Repeat(",?", len(orderIDs)-1) + `)
-        GROUP BY oi.OrderId, oi.StoreItemId`
+func (t *Database) SetOrderListState(orderListId string, state model.ListState) error {
+	res, err := t.conn.Exec(`UPDATE "OrderList" SET "State" = ? WHERE "Id" = ?`, state, orderListId)
+	if err != nil {
+		return wrapErr(err)
+	}
+	return wrapErr(checkAffected(res))
+}
 
-	itemRows, err := t.conn.Query(query, orderIDs...)
+func (t *Database) GetOrders(orderListId string) ([]*model.Order, error) {
+	rows, err := t.conn.Query(`
+        SELECT o.Id, o.Created, o.Creator, o.EditKey, o.Paid, o.PaidAmount, o.PaymentMethod, o.Note
+        FROM "Order" o 
+        WHERE o.OrderListId = ?
+        ORDER BY o.Created`, orderListId)
 	if err != nil {
 		return nil, wrapErr(err)
 	}
-	defer itemRows.Close()
+	defer rows.Close()
 
-	for itemRows.Next() {
-		var orderId, storeItemId string
-		var variants, dips sql.NullString
-		if err := itemRows.Scan(&orderId, &storeItemId, &variants, &dips); err != nil {
+	var orders []*model.Order
+	for rows.Next() {
+		var order model.Order
+		if err := rows.Scan(&order.Id, &order.Created, &order.Creator, &order.EditKey,
+			&order.Payment.Paid, &order.Payment.PaidCents, &order.Payment.Method, &order.Note); err != nil {
 			return nil, wrapErr(err)
 		}
-		item := &model.StoreItem{Id: storeItemId}
-		if variants.Valid {
-			item.Variants = strings.Split(variants.String, ",")
-		}
-		if dips.Valid {
-			item.Dips = strings.Split(dips.String, ",")
-		}
-		if order, ok := ordersMap[orderId]; ok {
-			order.StoreItems = append(order.StoreItems, item)
-		}
+		orders = append(orders, &order)
 	}
-	if err = itemRows.Err(); err != nil {
+	if err = rows.Err(); err != nil {
 		return nil, wrapErr(err)
 	}
+	if len(orders) == 0 {
+		return []*model.Order{}, nil
+	}
 
-	finalOrders := make([]*model.Order, 0, len(ordersMap))
-	for _, order := range ordersMap {
-		finalOrders = append(finalOrders, order)
+	if err = t.loadOrderLines(orders...); err != nil {
+		return nil, err
+	}
+	if err = t.loadOrderDrinks(orders...); err != nil {
+		return nil, err
 	}
-	return finalOrders, nil
+
+	return orders, nil
 }
 
 func (t *Database) GetOrder(orderListId, orderId string) (*model.Order, error) {
 	var (
-		order     model.Order
-		editKey   sql.NullString
-		drinkName sql.NullString
-		drinkSize sql.NullInt64
+		order   model.Order
+		editKey sql.NullString
 	)
 
 	err := t.conn.QueryRow(`
-		SELECT o.Id, o.Created, o.Creator, o.EditKey, d.Name, d.Size 
+		SELECT o.Id, o.Created, o.Creator, o.EditKey, o.Paid, o.PaidAmount, o.PaymentMethod, o.Note
 		FROM "Order" o 
-		LEFT JOIN "Drink" d ON d.Id = o.DrinkId 
 		WHERE o.OrderListId = ? AND o.Id = ?`, orderListId, orderId).
-		Scan(&order.Id, &order.Created, &order.Creator, &editKey, &drinkName, &drinkSize)
+		Scan(&order.Id, &order.Created, &order.Creator, &editKey,
+			&order.Payment.Paid, &order.Payment.PaidCents, &order.Payment.Method, &order.Note)
 
 	if err != nil {
 		return nil, wrapErr(err)
@@ -213,113 +282,435 @@ func (t *Database) GetOrder(orderListId, orderId string) (*model.Order, error) {
 	if editKey.Valid {
 		order.EditKey = editKey.String
 	}
-	if drinkName.Valid {
-		order.Drink = &model.Drink{Name: drinkName.String, Size: model.DrinkSize(drinkSize.Int64)}
+
+	if err = t.loadOrderLines(&order); err != nil {
+		return nil, err
+	}
+	if err = t.loadOrderDrinks(&order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// loadOrderLines sets the store items of the given orders from their
+// order lines.
+func (t *Database) loadOrderLines(orders ...*model.Order) error {
+	ordersById := make(map[string]*model.Order, len(orders))
+	orderIds := make([]any, 0, len(orders))
+	for _, order := range orders {
+		order.StoreItems = []*model.StoreItem{}
+		ordersById[order.Id] = order
+		orderIds = append(orderIds, order.Id)
 	}
+	in := `(?` + strings.Repeat(",?", len(orderIds)-1) + `)`
 
 	rows, err := t.conn.Query(`
-		SELECT oi.StoreItemId, 
-			   GROUP_CONCAT(DISTINCT sv.Variant) as variants, 
-			   GROUP_CONCAT(DISTINCT sd.Dip) as dips
-		FROM OrderItems oi
-		LEFT JOIN StoreItemVariant sv ON sv.OrderId = oi.OrderId AND sv.StoreItemId = oi.StoreItemId
-		LEFT JOIN StoreItemDip sd ON sd.OrderId = oi.OrderId AND sd.StoreItemId = oi.StoreItemId
-		WHERE oi.OrderId = ?
-		GROUP BY oi.StoreItemId`, orderId)
+		SELECT "Id", "OrderId", "StoreItemId", "Quantity", "Note", "SurpriseItemId", "SurpriseTitle"
+		FROM "OrderLine"
+		WHERE "OrderId" IN `+in+`
+		ORDER BY "OrderId", "Position"`, orderIds...)
 	if err != nil {
-		return nil, wrapErr(err)
+		return wrapErr(err)
 	}
 	defer rows.Close()
 
+	lines := make(map[string]*model.StoreItem)
 	for rows.Next() {
-		var storeItemId string
-		var variants, dips sql.NullString
-		if err := rows.Scan(&storeItemId, &variants, &dips); err != nil {
-			return nil, wrapErr(err)
+		var orderId string
+		var line model.StoreItem
+		var surprise model.Surprise
+		if err = rows.Scan(&line.LineId, &orderId, &line.Id, &line.Quantity, &line.Note, &surprise.Id, &surprise.Title); err != nil {
+			return wrapErr(err)
+		}
+		if surprise.Id != "" {
+			line.Surprise = &surprise
 		}
+		order := ordersById[orderId]
+		order.StoreItems = append(order.StoreItems, &line)
+		lines[line.LineId] = &line
+	}
+	if err = rows.Err(); err != nil {
+		return wrapErr(err)
+	}
 
-		item := &model.StoreItem{Id: storeItemId}
-		if variants.Valid {
-			item.Variants = strings.Split(variants.String, ",")
+	err = t.loadLineOptions(`
+		SELECT v."LineId", v."Variant"
+		FROM "OrderLineVariant" v
+		JOIN "OrderLine" l ON l."Id" = v."LineId"
+		WHERE l."OrderId" IN `+in+`
+		ORDER BY v."Variant"`, orderIds, func(lineId, variant string) {
+		if line, ok := lines[lineId]; ok {
+			line.Variants = append(line.Variants, variant)
 		}
-		if dips.Valid {
-			item.Dips = strings.Split(dips.String, ",")
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.loadLineOptions(`
+		SELECT d."LineId", d."Dip"
+		FROM "OrderLineDip" d
+		JOIN "OrderLine" l ON l."Id" = d."LineId"
+		WHERE l."OrderId" IN `+in+`
+		ORDER BY d."Dip"`, orderIds, func(lineId, dip string) {
+		if line, ok := lines[lineId]; ok {
+			line.Dips = append(line.Dips, dip)
 		}
-		order.StoreItems = append(order.StoreItems, item)
+	})
+	if err != nil {
+		return err
 	}
 
-	return &order, nil
+	return t.loadLineOptions(`
+		SELECT v."LineId", v."Variant"
+		FROM "OrderLineSurpriseVariant" v
+		JOIN "OrderLine" l ON l."Id" = v."LineId"
+		WHERE l."OrderId" IN `+in+`
+		ORDER BY v."Variant"`, orderIds, func(lineId, variant string) {
+		if line, ok := lines[lineId]; ok && line.Surprise != nil {
+			line.Surprise.Variants = append(line.Surprise.Variants, variant)
+		}
+	})
 }
 
-func (t *Database) UpdateOrder(orderListId string, order *model.Order) error {
-	tx, err := t.conn.BeginTx(context.TODO(), nil)
+// loadOrderDrinks sets the drink lines of the given orders.
+func (t *Database) loadOrderDrinks(orders ...*model.Order) error {
+	ordersById := make(map[string]*model.Order, len(orders))
+	orderIds := make([]any, 0, len(orders))
+	for _, order := range orders {
+		order.Drinks = nil
+		ordersById[order.Id] = order
+		orderIds = append(orderIds, order.Id)
+	}
+
+	rows, err := t.conn.Query(`
+		SELECT "OrderId", "MenuId", "Name", "Size", "Quantity"
+		FROM "OrderDrink"
+		WHERE "OrderId" IN (?`+strings.Repeat(",?", len(orderIds)-1)+`)
+		ORDER BY "OrderId", "Position"`, orderIds...)
 	if err != nil {
 		return wrapErr(err)
 	}
-	defer tx.Rollback()
+	defer rows.Close()
 
-	if _, err = tx.Exec(`DELETE FROM "OrderItems" WHERE "OrderId" = ?`, order.Id); err != nil {
-		return wrapErr(err)
+	for rows.Next() {
+		var orderId string
+		var drink model.Drink
+		if err = rows.Scan(&orderId, &drink.Id, &drink.Name, &drink.Size, &drink.Quantity); err != nil {
+			return wrapErr(err)
+		}
+		order := ordersById[orderId]
+		order.Drinks = append(order.Drinks, &drink)
 	}
-	if _, err = tx.Exec(`DELETE FROM "StoreItemVariant" WHERE "OrderId" = ?`, order.Id); err != nil {
+	if err = rows.Err(); err != nil {
 		return wrapErr(err)
 	}
-	if _, err = tx.Exec(`DELETE FROM "StoreItemDip" WHERE "OrderId" = ?`, order.Id); err != nil {
-		return wrapErr(err)
+
+	for _, order := range orders {
+		order.SyncLegacyDrink()
 	}
-	if _, err = tx.Exec(`DELETE FROM "Drink" WHERE "Id" = (SELECT "DrinkId" FROM "Order" WHERE "Id" = ?)`, order.Id); err != nil {
+	return nil
+}
+
+func (t *Database) loadLineOptions(query string, args []any, add func(lineId, value string)) error {
+	rows, err := t.conn.Query(query, args...)
+	if err != nil {
 		return wrapErr(err)
 	}
+	defer rows.Close()
 
-	var drinkId sql.NullString
-	if order.Drink != nil {
-		drinkId.String = uuid.New().String()
-		drinkId.Valid = true
-		_, err = tx.Exec(`INSERT INTO "Drink" ("Id", "Name", "Size") VALUES (?, ?, ?);`, drinkId.String, order.Drink.Name, order.Drink.Size)
-		if err != nil {
+	for rows.Next() {
+		var lineId, value string
+		if err = rows.Scan(&lineId, &value); err != nil {
 			return wrapErr(err)
 		}
+		add(lineId, value)
+	}
+	return wrapErr(rows.Err())
+}
+
+func (t *Database) UpdateOrder(orderListId string, order *model.Order) error {
+	tx, err := t.conn.BeginTx(context.TODO(), nil)
+	if err != nil {
+		return wrapErr(err)
+	}
+	defer tx.Rollback()
+
+	if err = deleteOrderLines(tx, `?`, order.Id); err != nil {
+		return err
 	}
 
 	_, err = tx.Exec(
-		`UPDATE "Order" SET "Creator" = ?, "DrinkId" = ? WHERE "Id" = ? AND "OrderListId" = ?`,
-		order.Creator, drinkId, order.Id, orderListId)
+		`UPDATE "Order" SET "Creator" = ?, "Note" = ? WHERE "Id" = ? AND "OrderListId" = ?`,
+		order.Creator, order.Note, order.Id, orderListId)
 	if err != nil {
 		return wrapErr(err)
 	}
 
-	for _, item := range order.StoreItems {
-		_, err = tx.Exec(`INSERT INTO "OrderItems" ("OrderId", "StoreItemId") VALUES (?, ?);`, order.Id, item.Id)
+	if err = insertOrderLines(tx, order); err != nil {
+		return err
+	}
+	if err = insertOrderDrinks(tx, order); err != nil {
+		return err
+	}
+
+	return wrapErr(tx.Commit())
+}
+
+// UpdateOrderLineSurprise stores the surprise of the given order line
+// without touching the rest of its order. The line is only updated if
+// it still orders the same store item and is still resolved to previous,
+// which is nil for unresolved lines; otherwise false is returned.
+func (t *Database) UpdateOrderLineSurprise(line *model.StoreItem, previous *model.Surprise) (bool, error) {
+	tx, err := t.conn.BeginTx(context.TODO(), nil)
+	if err != nil {
+		return false, wrapErr(err)
+	}
+	defer tx.Rollback()
+
+	var previousId string
+	if previous != nil {
+		previousId = previous.Id
+	}
+	var surprise model.Surprise
+	if line.Surprise != nil {
+		surprise = *line.Surprise
+	}
+
+	res, err := tx.Exec(
+		`UPDATE "OrderLine" SET "SurpriseItemId" = ?, "SurpriseTitle" = ?
+		 WHERE "Id" = ? AND "StoreItemId" = ? AND "SurpriseItemId" = ?`,
+		surprise.Id, surprise.Title, line.LineId, line.Id, previousId)
+	if err != nil {
+		return false, wrapErr(err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, wrapErr(err)
+	}
+
+	if _, err = tx.Exec(`DELETE FROM "OrderLineSurpriseVariant" WHERE "LineId" = ?`, line.LineId); err != nil {
+		return false, wrapErr(err)
+	}
+	for _, variant := range surprise.Variants {
+		_, err = tx.Exec(
+			`INSERT INTO "OrderLineSurpriseVariant" ("LineId", "Variant") VALUES (?, ?);`,
+			line.LineId, variant)
+		if err != nil {
+			return false, wrapErr(err)
+		}
+	}
+
+	return true, wrapErr(tx.Commit())
+}
+
+// insertOrderLines stores the store items of the order as order lines
+// in the order they are given.
+func insertOrderLines(tx *sql.Tx, order *model.Order) error {
+	for i, item := range order.StoreItems {
+		var surprise model.Surprise
+		if item.Surprise != nil {
+			surprise = *item.Surprise
+		}
+		_, err := tx.Exec(
+			`INSERT INTO "OrderLine" ("Id", "OrderId", "StoreItemId", "Quantity", "Note", "SurpriseItemId", "SurpriseTitle", "Position")
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+			item.LineId, order.Id, item.Id, item.Quantity, item.Note, surprise.Id, surprise.Title, i)
 		if err != nil {
 			return wrapErr(err)
 		}
+		for _, variant := range surprise.Variants {
+			_, err = tx.Exec(
+				`INSERT INTO "OrderLineSurpriseVariant" ("LineId", "Variant") VALUES (?, ?);`,
+				item.LineId, variant)
+			if err != nil {
+				return wrapErr(err)
+			}
+		}
 		for _, variant := range item.Variants {
-			_, err = tx.Exec(`INSERT INTO "StoreItemVariant" ("OrderId", "StoreItemId", "Variant") VALUES (?, ?, ?);`, order.Id, item.Id, variant)
+			_, err = tx.Exec(
+				`INSERT INTO "OrderLineVariant" ("LineId", "Variant") VALUES (?, ?);`,
+				item.LineId, variant)
 			if err != nil {
 				return wrapErr(err)
 			}
 		}
 		for _, dip := range item.Dips {
-			_, err = tx.Exec(`INSERT INTO "StoreItemDip" ("OrderId", "StoreItemId", "Dip") VALUES (?, ?, ?);`, order.Id, item.Id, dip)
+			_, err = tx.Exec(
+				`INSERT INTO "OrderLineDip" ("LineId", "Dip") VALUES (?, ?);`,
+				item.LineId, dip)
 			if err != nil {
 				return wrapErr(err)
 			}
 		}
 	}
+	return nil
+}
 
-	return wrapErr(tx.Commit())
+// insertOrderDrinks stores the drink lines of the order in the order
+// they are given.
+func insertOrderDrinks(tx *sql.Tx, order *model.Order) error {
+	for i, drink := range order.Drinks {
+		_, err := tx.Exec(
+			`INSERT INTO "OrderDrink" ("Id", "OrderId", "MenuId", "Name", "Size", "Quantity", "Position") VALUES (?, ?, ?, ?, ?, ?, ?);`,
+			uuid.New().String(), order.Id, drink.Id, drink.Name, drink.Size, drink.Quantity, i)
+		if err != nil {
+			return wrapErr(err)
+		}
+	}
+	return nil
 }
 
+func (t *Database) UpdatePayment(orderListId, orderId string, payment *model.Payment) error {
+	res, err := t.conn.Exec(
+		`UPDATE "Order" SET "Paid" = ?, "PaidAmount" = ?, "PaymentMethod" = ? WHERE "Id" = ? AND "OrderListId" = ?`,
+		payment.Paid, payment.PaidCents, payment.Method, orderId, orderListId)
+	if err != nil {
+		return wrapErr(err)
+	}
+	return wrapErr(checkAffected(res))
+}
+
+// DeleteOrderList deletes the order list with all of its orders.
 func (t *Database) DeleteOrderList(orderListId string) error {
-	_, err := t.conn.Exec(`DELETE FROM "OrderList" WHERE "Id" = ?`, orderListId)
-	return wrapErr(err)
+	tx, err := t.conn.BeginTx(context.TODO(), nil)
+	if err != nil {
+		return wrapErr(err)
+	}
+	defer tx.Rollback()
+
+	orderIds := `SELECT "Id" FROM "Order" WHERE "OrderListId" = ?`
+	if err = deleteOrderLines(tx, orderIds, orderListId); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(`DELETE FROM "Order" WHERE "OrderListId" = ?`, orderListId); err != nil {
+		return wrapErr(err)
+	}
+	if _, err = tx.Exec(`DELETE FROM "OrderList" WHERE "Id" = ?`, orderListId); err != nil {
+		return wrapErr(err)
+	}
+
+	return wrapErr(tx.Commit())
 }
 
+// DeleteOrder deletes the order with its lines and drinks.
 func (t *Database) DeleteOrder(orderListId, orderId string) error {
-	_, err := t.conn.Exec(`DELETE FROM "Order" WHERE "Id" = ? AND "OrderListId" = ?`, orderId, orderListId)
+	tx, err := t.conn.BeginTx(context.TODO(), nil)
+	if err != nil {
+		return wrapErr(err)
+	}
+	defer tx.Rollback()
+
+	orderIds := `SELECT "Id" FROM "Order" WHERE "Id" = ? AND "OrderListId" = ?`
+	if err = deleteOrderLines(tx, orderIds, orderId, orderListId); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(`DELETE FROM "Order" WHERE "Id" = ? AND "OrderListId" = ?`, orderId, orderListId); err != nil {
+		return wrapErr(err)
+	}
+
+	return wrapErr(tx.Commit())
+}
+
+// deleteOrderLines deletes the lines and drinks of the orders whose IDs
+// are selected by the given query. Foreign keys are not enforced, so the
+// rows referencing the lines are deleted explicitly.
+func deleteOrderLines(tx *sql.Tx, orderIds string, args ...any) error {
+	lineIds := `SELECT "Id" FROM "OrderLine" WHERE "OrderId" IN (` + orderIds + `)`
+	queries := []string{
+		`DELETE FROM "OrderLineVariant" WHERE "LineId" IN (` + lineIds + `)`,
+		`DELETE FROM "OrderLineDip" WHERE "LineId" IN (` + lineIds + `)`,
+		`DELETE FROM "OrderLineSurpriseVariant" WHERE "LineId" IN (` + lineIds + `)`,
+		`DELETE FROM "OrderLine" WHERE "OrderId" IN (` + orderIds + `)`,
+		`DELETE FROM "OrderDrink" WHERE "OrderId" IN (` + orderIds + `)`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, args...); err != nil {
+			return wrapErr(err)
+		}
+	}
+	return nil
+}
+
+func (t *Database) CreateSchedule(schedule *model.Schedule) error {
+	_, err := t.conn.Exec(
+		`INSERT INTO "Schedule" ("Id", "Created", "Title", "Cron", "DeadlineOffset", "ManagementKey") VALUES (?, ?, ?, ?, ?, ?);`,
+		schedule.Id, schedule.Created.UTC(), schedule.Title, schedule.Cron, schedule.DeadlineOffsetMinutes, schedule.ManagementKey)
 	return wrapErr(err)
 }
 
+func (t *Database) GetSchedule(scheduleId string) (*model.Schedule, error) {
+	row := t.conn.QueryRow(
+		`SELECT "Id", "Created", "Title", "Cron", "DeadlineOffset", "ManagementKey", "LastRun" FROM "Schedule" WHERE "Id" = ?`,
+		scheduleId)
+	return scanSchedule(row)
+}
+
+func (t *Database) GetSchedules() ([]*model.Schedule, error) {
+	rows, err := t.conn.Query(
+		`SELECT "Id", "Created", "Title", "Cron", "DeadlineOffset", "ManagementKey", "LastRun" FROM "Schedule" ORDER BY "Created"`)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	defer rows.Close()
+
+	var schedules []*model.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, wrapErr(rows.Err())
+}
+
+// CreateScheduledOrderList creates the order list of a run of its
+// schedule and records the run, so that a run either creates its list
+// and is recorded or does neither.
+func (t *Database) CreateScheduledOrderList(list *model.OrderList, lastRun time.Time) error {
+	tx, err := t.conn.BeginTx(context.TODO(), nil)
+	if err != nil {
+		return wrapErr(err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(insertOrderList, orderListValues(list)...); err != nil {
+		return wrapErr(err)
+	}
+	res, err := tx.Exec(`UPDATE "Schedule" SET "LastRun" = ? WHERE "Id" = ?`, lastRun.UTC(), list.ScheduleId)
+	if err != nil {
+		return wrapErr(err)
+	}
+	if err = checkAffected(res); err != nil {
+		return wrapErr(err)
+	}
+
+	return wrapErr(tx.Commit())
+}
+
+func (t *Database) DeleteSchedule(scheduleId string) error {
+	res, err := t.conn.Exec(`DELETE FROM "Schedule" WHERE "Id" = ?`, scheduleId)
+	if err != nil {
+		return wrapErr(err)
+	}
+	return wrapErr(checkAffected(res))
+}
+
+func scanSchedule(row scanner) (*model.Schedule, error) {
+	var schedule model.Schedule
+	var lastRun sql.NullTime
+	err := row.Scan(&schedule.Id, &schedule.Created, &schedule.Title, &schedule.Cron,
+		&schedule.DeadlineOffsetMinutes, &schedule.ManagementKey, &lastRun)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	if lastRun.Valid {
+		schedule.LastRun = &lastRun.Time
+	}
+	return &schedule, nil
+}
+
 func (t *Database) ClearAllData() error {
 	tx, err := t.conn.BeginTx(context.TODO(), nil)
 	if err != nil {
@@ -327,7 +718,7 @@ func (t *Database) ClearAllData() error {
 	}
 	defer tx.Rollback()
 
-	tables := []string{"OrderItems", "StoreItemDip", "StoreItemVariant", "Drink", "Order", "OrderList"}
+	tables := []string{"OrderLineVariant", "OrderLineDip", "OrderLineSurpriseVariant", "OrderLine", "OrderDrink", "Order", "OrderList", "Schedule"}
 	for _, tbl := range tables {
 		if _, err := tx.Exec(`DELETE FROM "` + tbl + `";`); err != nil {
 			return wrapErr(err)
@@ -342,7 +733,7 @@ func (t *Database) ClearAllData() error {
 func (t *Database) CreateFeedback(feedback *model.Feedback) error {
 	_, err := t.conn.Exec(
 		`INSERT INTO "Feedback" ("Id", "Timestamp", "Type", "Message", "Page") VALUES (?, ?, ?, ?, ?);`,
-		feedback.Id, feedback.Timestamp, feedback.Type, feedback.Message, feedback.Page)
+		feedback.Id, feedback.Timestamp.UTC(), feedback.Type, feedback.Message, feedback.Page)
 	return wrapErr(err)
 }
 
@@ -363,3 +754,23 @@ func (t *Database) GetAllFeedback() ([]*model.Feedback, error) {
 	}
 	return feedbacks, nil
 }
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// nullTime stores nil times as NULL. Like all times, others are stored
+// in UTC, as their text representations only compare correctly with the
+// same offset.
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// nullString stores empty strings as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,386 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+func newTestDatabase(t *testing.T) *Database {
+	db, err := New(filepath.Join(t.TempDir(), "db.sqlite"))
+	if err != nil {
+		t.Fatalf("creating database failed: %v", err)
+	}
+	t.Cleanup(func() { db.conn.Close() })
+	return db
+}
+
+func createList(t *testing.T, db *Database, id string, created time.Time, deadline *time.Time, state model.ListState) {
+	err := db.CreateOrderList(&model.OrderList{
+		Id:       id,
+		Created:  created,
+		Deadline: deadline,
+		State:    state,
+	})
+	if err != nil {
+		t.Fatalf("creating list %s failed: %v", id, err)
+	}
+}
+
+func listIds(lists []*model.OrderListSummary) []string {
+	ids := make([]string, 0, len(lists))
+	for _, list := range lists {
+		ids = append(ids, list.Id)
+	}
+	return ids
+}
+
+// Times with far apart offsets do not sort correctly by their local
+// text representation.
+var (
+	east = time.FixedZone("UTC+14", 14*60*60)
+	west = time.FixedZone("UTC-12", -12*60*60)
+)
+
+func TestListOrderListsStatus(t *testing.T) {
+	db := newTestDatabase(t)
+
+	now := time.Now()
+	future := now.Add(30 * time.Minute).In(west)
+	past := now.Add(-30 * time.Minute).In(east)
+
+	createList(t, db, "open", now, nil, model.ListStateOpen)
+	createList(t, db, "open-deadline", now, &future, model.ListStateOpen)
+	createList(t, db, "exceeded", now, &past, model.ListStateOpen)
+	createList(t, db, "locked", now, &future, model.ListStateLocked)
+
+	tests := []struct {
+		status model.ListStatus
+		want   []string
+	}{
+		{status: model.ListStatusOpen, want: []string{"open", "open-deadline"}},
+		{status: model.ListStatusClosed, want: []string{"exceeded", "locked"}},
+		{status: "", want: []string{"exceeded", "locked", "open", "open-deadline"}},
+	}
+
+	for _, tt := range tests {
+		lists, err := db.ListOrderLists(&model.ListFilter{Status: tt.status, SortBy: model.ListSortCreated, Limit: 100})
+		if err != nil {
+			t.Fatalf("listing %q lists failed: %v", tt.status, err)
+		}
+		ids := listIds(lists)
+		slices.Sort(ids)
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("%q lists: expected %v, got %v", tt.status, tt.want, ids)
+		}
+	}
+}
+
+func TestListOrderListsCreatedRange(t *testing.T) {
+	db := newTestDatabase(t)
+
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	createList(t, db, "a", base.Add(-2*time.Hour).In(east), nil, model.ListStateOpen)
+	createList(t, db, "b", base.In(west), nil, model.ListStateOpen)
+	createList(t, db, "c", base.Add(2*time.Hour).In(east), nil, model.ListStateOpen)
+
+	after := base.Add(-time.Hour).In(west)
+	before := base.Add(time.Hour).In(east)
+	lists, err := db.ListOrderLists(&model.ListFilter{
+		CreatedAfter:  &after,
+		CreatedBefore: &before,
+		SortBy:        model.ListSortCreated,
+		Limit:         100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := listIds(lists); !slices.Equal(ids, []string{"b"}) {
+		t.Errorf("expected [b], got %v", ids)
+	}
+}
+
+func TestListOrderListsCursor(t *testing.T) {
+	db := newTestDatabase(t)
+
+	// The lists are created with alternating offsets, so that their local
+	// times are in a different order than the instants.
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var byCreated, byDeadline []string
+	for i := range 7 {
+		id := fmt.Sprintf("list-%d", i)
+		zone := east
+		if i%2 == 1 {
+			zone = west
+		}
+		created := base.Add(time.Duration(i) * time.Hour).In(zone)
+		// Two lists share a creation time to cover the ID tiebreaker.
+		if i == 4 {
+			created = base.Add(3 * time.Hour).In(east)
+		}
+		var deadline *time.Time
+		if i < 5 {
+			d := base.Add(time.Duration(10-i) * time.Hour).In(zone)
+			deadline = &d
+			byDeadline = append([]string{id}, byDeadline...)
+		}
+		createList(t, db, id, created, deadline, model.ListStateOpen)
+		byCreated = append(byCreated, id)
+	}
+	byDeadline = append(byDeadline, "list-5", "list-6")
+
+	tests := []struct {
+		sortBy     model.ListSortField
+		descending bool
+		want       []string
+	}{
+		{sortBy: model.ListSortCreated, want: byCreated},
+		{sortBy: model.ListSortCreated, descending: true, want: reversed(byCreated)},
+		{sortBy: model.ListSortDeadline, want: byDeadline},
+		{sortBy: model.ListSortDeadline, descending: true, want: reversed(byDeadline)},
+	}
+
+	for _, tt := range tests {
+		name := string(tt.sortBy)
+		if tt.descending {
+			name += "-desc"
+		}
+		t.Run(name, func(t *testing.T) {
+			filter := &model.ListFilter{SortBy: tt.sortBy, Descending: tt.descending, Limit: 2}
+			var ids []string
+			for page := 0; ; page++ {
+				if page > len(tt.want) {
+					t.Fatalf("paging did not terminate, got %v", ids)
+				}
+				lists, err := db.ListOrderLists(filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(lists) == 0 {
+					break
+				}
+				ids = append(ids, listIds(lists)...)
+
+				// The cursor is passed to the client and back.
+				last := lists[len(lists)-1]
+				cursor := model.ListCursor{Value: last.CursorValue(tt.sortBy), Id: last.Id}
+				filter.After, err = model.ParseListCursor(cursor.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+func reversed(s []string) []string {
+	s = slices.Clone(s)
+	slices.Reverse(s)
+	return s
+}
+
+func TestMigrateTimesToUTC(t *testing.T) {
+	conn, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "db.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	goose.SetBaseFS(migrationsFs)
+	if err = goose.SetDialect("sqlite"); err != nil {
+		t.Fatal(err)
+	}
+	if err = goose.UpTo(conn, "migrations", 18); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = conn.Exec(`INSERT INTO "OrderList" ("Id", "Created", "Deadline", "State") VALUES
+		('a', '2025-06-01 14:00:00.123456789+02:00', '2025-06-01 10:30:00-12:00', 'open'),
+		('b', '2025-06-01 12:00:00.5+00:00', NULL, 'open')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id       string
+		created  string
+		deadline sql.NullString
+	}{
+		{id: "a", created: "2025-06-01 12:00:00.123456789+00:00",
+			deadline: sql.NullString{String: "2025-06-01 22:30:00+00:00", Valid: true}},
+		{id: "b", created: "2025-06-01 12:00:00.5+00:00"},
+	}
+	for _, tt := range tests {
+		var created string
+		var deadline sql.NullString
+		err = conn.QueryRow(`SELECT CAST("Created" AS TEXT), CAST("Deadline" AS TEXT) FROM "OrderList" WHERE "Id" = ?`, tt.id).
+			Scan(&created, &deadline)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created != tt.created || deadline != tt.deadline {
+			t.Errorf("list %s: expected %s/%v, got %s/%v", tt.id, tt.created, tt.deadline, created, deadline)
+		}
+	}
+}
+
+func createOrder(t *testing.T, db *Database, listId, id string) {
+	err := db.CreateOrder(listId, &model.Order{
+		Id:      id,
+		Created: time.Now(),
+		Creator: "creator",
+		StoreItems: []*model.StoreItem{{
+			LineId:   id + "-line",
+			Id:       "item",
+			Variants: []string{"variant"},
+			Dips:     []string{"dip"},
+			Surprise: &model.Surprise{Id: "dish", Variants: []string{"variant"}},
+		}},
+		Drinks: []*model.Drink{{Id: "drink", Name: "Cola"}},
+	})
+	if err != nil {
+		t.Fatalf("creating order %s failed: %v", id, err)
+	}
+}
+
+// countRows returns the number of rows per order table.
+func countRows(t *testing.T, db *Database) map[string]int {
+	counts := make(map[string]int)
+	for _, tbl := range []string{"Order", "OrderLine", "OrderLineVariant", "OrderLineDip",
+		"OrderLineSurpriseVariant", "OrderDrink"} {
+		var n int
+		if err := db.conn.QueryRow(`SELECT COUNT(*) FROM "` + tbl + `"`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		counts[tbl] = n
+	}
+	return counts
+}
+
+func TestDeleteOrder(t *testing.T) {
+	db := newTestDatabase(t)
+
+	createList(t, db, "list", time.Now(), nil, model.ListStateOpen)
+	createOrder(t, db, "list", "a")
+	createOrder(t, db, "list", "b")
+
+	if err := db.DeleteOrder("list", "a"); err != nil {
+		t.Fatal(err)
+	}
+	for tbl, n := range countRows(t, db) {
+		if n != 1 {
+			t.Errorf("expected 1 row in %s, got %d", tbl, n)
+		}
+	}
+}
+
+func TestDeleteOrderList(t *testing.T) {
+	db := newTestDatabase(t)
+
+	createList(t, db, "list", time.Now(), nil, model.ListStateOpen)
+	createList(t, db, "other", time.Now(), nil, model.ListStateOpen)
+	createOrder(t, db, "list", "a")
+	createOrder(t, db, "list", "b")
+	createOrder(t, db, "other", "c")
+
+	if err := db.DeleteOrderList("list"); err != nil {
+		t.Fatal(err)
+	}
+	for tbl, n := range countRows(t, db) {
+		if n != 1 {
+			t.Errorf("expected 1 row in %s, got %d", tbl, n)
+		}
+	}
+	if _, err := db.GetOrderList("list"); err == nil {
+		t.Error("expected deleted list to be gone")
+	}
+}
+
+func TestCreateScheduledOrderList(t *testing.T) {
+	db := newTestDatabase(t)
+
+	err := db.CreateSchedule(&model.Schedule{Id: "schedule", Created: time.Now(), Title: "Mittag", Cron: "0 11 * * 1-5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lastRun := time.Date(2025, 6, 2, 11, 0, 0, 0, time.UTC)
+	list := &model.OrderList{Id: "list", Created: lastRun, State: model.ListStateOpen, ScheduleId: "schedule"}
+	if err = db.CreateScheduledOrderList(list, lastRun); err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := db.GetSchedule("schedule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.LastRun == nil || !schedule.LastRun.Equal(lastRun) {
+		t.Errorf("expected last run %v, got %v", lastRun, schedule.LastRun)
+	}
+
+	// The list is not created if the run can not be recorded.
+	list = &model.OrderList{Id: "orphan", Created: lastRun, State: model.ListStateOpen, ScheduleId: "unknown"}
+	if err = db.CreateScheduledOrderList(list, lastRun); err == nil {
+		t.Fatal("expected error for unknown schedule")
+	}
+	if _, err = db.GetOrderList("orphan"); err == nil {
+		t.Error("expected list of failed run not to be created")
+	}
+}
+
+func TestUpdateOrderLineSurprise(t *testing.T) {
+	db := newTestDatabase(t)
+
+	createList(t, db, "list", time.Now(), nil, model.ListStateOpen)
+	createOrder(t, db, "list", "a")
+
+	resolved := &model.Surprise{Id: "dish", Variants: []string{"variant"}}
+	line := &model.StoreItem{LineId: "a-line", Id: "item",
+		Surprise: &model.Surprise{Id: "other", Title: "Other", Variants: []string{"veggie"}}}
+
+	tests := []struct {
+		name     string
+		line     *model.StoreItem
+		previous *model.Surprise
+		updated  bool
+	}{
+		{name: "changed item", line: &model.StoreItem{LineId: "a-line", Id: "changed", Surprise: line.Surprise},
+			previous: resolved},
+		{name: "changed surprise", line: line, previous: nil},
+		{name: "unchanged", line: line, previous: resolved, updated: true},
+	}
+	for _, tt := range tests {
+		updated, err := db.UpdateOrderLineSurprise(tt.line, tt.previous)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated != tt.updated {
+			t.Errorf("%s: expected updated=%t, got %t", tt.name, tt.updated, updated)
+		}
+	}
+
+	order, err := db.GetOrder("list", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	surprise := order.StoreItems[0].Surprise
+	if surprise == nil || surprise.Id != "other" || surprise.Title != "Other" ||
+		!slices.Equal(surprise.Variants, []string{"veggie"}) {
+		t.Errorf("unexpected surprise: %+v", surprise)
+	}
+	if !slices.Equal(order.StoreItems[0].Variants, []string{"variant"}) || len(order.Drinks) != 1 {
+		t.Errorf("order has been changed apart from the surprise: %+v", order)
+	}
+}
@@ -23,3 +23,14 @@ func wrapErr(err error) error {
 
 	return elk.Wrap(ErrDatabase, err, "database error")
 }
+
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
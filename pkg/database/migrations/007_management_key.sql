@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE "OrderList" ADD COLUMN "ManagementKey" TEXT;
+
+-- +goose Down
+ALTER TABLE "OrderList" DROP COLUMN "ManagementKey";
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE "Order" ADD COLUMN "Paid" BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE "Order" ADD COLUMN "PaidAmount" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "Order" ADD COLUMN "PaymentMethod" TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE "Order" DROP COLUMN "PaymentMethod";
+ALTER TABLE "Order" DROP COLUMN "PaidAmount";
+ALTER TABLE "Order" DROP COLUMN "Paid";
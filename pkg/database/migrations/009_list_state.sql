@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE "OrderList" ADD COLUMN "State" TEXT NOT NULL DEFAULT 'open';
+
+-- +goose Down
+ALTER TABLE "OrderList" DROP COLUMN "State";
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE "Schedule" (
+    "Id"             TEXT NOT NULL PRIMARY KEY,
+    "Created"        DATETIME NOT NULL,
+    "Title"          TEXT NOT NULL,
+    "Cron"           TEXT NOT NULL,
+    "DeadlineOffset" INTEGER NOT NULL DEFAULT 0,
+    "ManagementKey"  TEXT NOT NULL,
+    "LastRun"        DATETIME NULL
+);
+
+ALTER TABLE "OrderList" ADD COLUMN "ScheduleId" TEXT NULL;
+CREATE INDEX "IdxOrderListScheduleId" ON "OrderList" ("ScheduleId", "Created");
+
+-- +goose Down
+DROP INDEX "IdxOrderListScheduleId";
+ALTER TABLE "OrderList" DROP COLUMN "ScheduleId";
+DROP TABLE "Schedule";
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE "OrderList" ADD COLUMN "Title" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "OrderList" ADD COLUMN "Description" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "OrderList" ADD COLUMN "Organizer" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "OrderList" ADD COLUMN "PickupTime" DATETIME NULL;
+ALTER TABLE "OrderList" ADD COLUMN "PickupLocation" TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE "OrderList" DROP COLUMN "PickupLocation";
+ALTER TABLE "OrderList" DROP COLUMN "PickupTime";
+ALTER TABLE "OrderList" DROP COLUMN "Organizer";
+ALTER TABLE "OrderList" DROP COLUMN "Description";
+ALTER TABLE "OrderList" DROP COLUMN "Title";
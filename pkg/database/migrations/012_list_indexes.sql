@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE INDEX "IdxOrderListCreated" ON "OrderList" ("Created", "Id");
+CREATE INDEX "IdxOrderListDeadline" ON "OrderList" ("Deadline");
+CREATE INDEX "IdxOrderListState" ON "OrderList" ("State");
+CREATE INDEX "IdxOrderOrderListId" ON "Order" ("OrderListId");
+
+-- +goose Down
+DROP INDEX "IdxOrderOrderListId";
+DROP INDEX "IdxOrderListState";
+DROP INDEX "IdxOrderListDeadline";
+DROP INDEX "IdxOrderListCreated";
@@ -0,0 +1,93 @@
+-- +goose Up
+CREATE TABLE "OrderItemsNew" (
+    "Id"          TEXT NOT NULL PRIMARY KEY,
+    "OrderId"     TEXT NOT NULL,
+    "StoreItemId" TEXT NOT NULL,
+    "Quantity"    INTEGER NOT NULL DEFAULT 1,
+    "Position"    INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY("OrderId") REFERENCES "Order"("Id") ON DELETE CASCADE
+);
+
+-- Multiple rows of the same store item in an order could not be told
+-- apart before, so they are merged into a single line.
+INSERT INTO "OrderItemsNew" ("Id", "OrderId", "StoreItemId", "Quantity", "Position")
+SELECT lower(hex(randomblob(16))), "OrderId", "StoreItemId", COUNT(*), MIN(rowid)
+FROM "OrderItems"
+GROUP BY "OrderId", "StoreItemId";
+
+DROP TABLE "OrderItems";
+ALTER TABLE "OrderItemsNew" RENAME TO "OrderItems";
+CREATE INDEX "IdxOrderItemsOrderId" ON "OrderItems" ("OrderId", "Position");
+
+CREATE TABLE "StoreItemVariantNew" (
+    "LineId"  TEXT NOT NULL,
+    "OrderId" TEXT NOT NULL,
+    "Variant" TEXT NOT NULL,
+    PRIMARY KEY ("LineId", "Variant"),
+    FOREIGN KEY ("LineId") REFERENCES "OrderItems"("Id") ON DELETE CASCADE
+);
+
+INSERT INTO "StoreItemVariantNew" ("LineId", "OrderId", "Variant")
+SELECT DISTINCT l."Id", v."OrderId", v."Variant"
+FROM "StoreItemVariant" v
+JOIN "OrderItems" l ON l."OrderId" = v."OrderId" AND l."StoreItemId" = v."StoreItemId";
+
+DROP TABLE "StoreItemVariant";
+ALTER TABLE "StoreItemVariantNew" RENAME TO "StoreItemVariant";
+CREATE INDEX "IdxStoreItemVariantOrderId" ON "StoreItemVariant" ("OrderId");
+
+CREATE TABLE "StoreItemDipNew" (
+    "LineId"  TEXT NOT NULL,
+    "OrderId" TEXT NOT NULL,
+    "Dip"     TEXT NOT NULL,
+    PRIMARY KEY ("LineId", "Dip"),
+    FOREIGN KEY ("LineId") REFERENCES "OrderItems"("Id") ON DELETE CASCADE
+);
+
+INSERT INTO "StoreItemDipNew" ("LineId", "OrderId", "Dip")
+SELECT DISTINCT l."Id", d."OrderId", d."Dip"
+FROM "StoreItemDip" d
+JOIN "OrderItems" l ON l."OrderId" = d."OrderId" AND l."StoreItemId" = d."StoreItemId";
+
+DROP TABLE "StoreItemDip";
+ALTER TABLE "StoreItemDipNew" RENAME TO "StoreItemDip";
+CREATE INDEX "IdxStoreItemDipOrderId" ON "StoreItemDip" ("OrderId");
+
+-- +goose Down
+CREATE TABLE "StoreItemDipOld" (
+    "OrderId"     TEXT NOT NULL,
+    "Dip"         TEXT NOT NULL,
+    "StoreItemId" TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY ("OrderId", "Dip"),
+    FOREIGN KEY ("OrderId") REFERENCES "Order"("Id") ON DELETE CASCADE
+);
+INSERT OR IGNORE INTO "StoreItemDipOld" ("OrderId", "Dip", "StoreItemId")
+SELECT d."OrderId", d."Dip", l."StoreItemId"
+FROM "StoreItemDip" d
+JOIN "OrderItems" l ON l."Id" = d."LineId";
+DROP TABLE "StoreItemDip";
+ALTER TABLE "StoreItemDipOld" RENAME TO "StoreItemDip";
+
+CREATE TABLE "StoreItemVariantOld" (
+    "OrderId"     TEXT NOT NULL,
+    "Variant"     TEXT NOT NULL,
+    "StoreItemId" TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY ("OrderId", "Variant"),
+    FOREIGN KEY ("OrderId") REFERENCES "Order"("Id") ON DELETE CASCADE
+);
+INSERT OR IGNORE INTO "StoreItemVariantOld" ("OrderId", "Variant", "StoreItemId")
+SELECT v."OrderId", v."Variant", l."StoreItemId"
+FROM "StoreItemVariant" v
+JOIN "OrderItems" l ON l."Id" = v."LineId";
+DROP TABLE "StoreItemVariant";
+ALTER TABLE "StoreItemVariantOld" RENAME TO "StoreItemVariant";
+
+CREATE TABLE "OrderItemsOld" (
+    "OrderId"     TEXT NOT NULL,
+    "StoreItemId" TEXT NOT NULL,
+    FOREIGN KEY("OrderId") REFERENCES "Order"("Id") ON DELETE CASCADE
+);
+INSERT INTO "OrderItemsOld" ("OrderId", "StoreItemId")
+SELECT "OrderId", "StoreItemId" FROM "OrderItems" ORDER BY "OrderId", "Position";
+DROP TABLE "OrderItems";
+ALTER TABLE "OrderItemsOld" RENAME TO "OrderItems";
@@ -0,0 +1,57 @@
+-- +goose Up
+ALTER TABLE "OrderItems" RENAME TO "OrderLine";
+DROP INDEX "IdxOrderItemsOrderId";
+CREATE INDEX "IdxOrderLineOrderId" ON "OrderLine" ("OrderId", "Position");
+
+CREATE TABLE "OrderLineVariant" (
+    "LineId"  TEXT NOT NULL,
+    "Variant" TEXT NOT NULL,
+    PRIMARY KEY ("LineId", "Variant"),
+    FOREIGN KEY ("LineId") REFERENCES "OrderLine"("Id") ON DELETE CASCADE
+);
+INSERT INTO "OrderLineVariant" ("LineId", "Variant")
+SELECT "LineId", "Variant" FROM "StoreItemVariant";
+DROP TABLE "StoreItemVariant";
+
+CREATE TABLE "OrderLineDip" (
+    "LineId" TEXT NOT NULL,
+    "Dip"    TEXT NOT NULL,
+    PRIMARY KEY ("LineId", "Dip"),
+    FOREIGN KEY ("LineId") REFERENCES "OrderLine"("Id") ON DELETE CASCADE
+);
+INSERT INTO "OrderLineDip" ("LineId", "Dip")
+SELECT "LineId", "Dip" FROM "StoreItemDip";
+DROP TABLE "StoreItemDip";
+
+-- +goose Down
+CREATE TABLE "StoreItemDip" (
+    "LineId"  TEXT NOT NULL,
+    "OrderId" TEXT NOT NULL,
+    "Dip"     TEXT NOT NULL,
+    PRIMARY KEY ("LineId", "Dip"),
+    FOREIGN KEY ("LineId") REFERENCES "OrderLine"("Id") ON DELETE CASCADE
+);
+INSERT INTO "StoreItemDip" ("LineId", "OrderId", "Dip")
+SELECT d."LineId", l."OrderId", d."Dip"
+FROM "OrderLineDip" d
+JOIN "OrderLine" l ON l."Id" = d."LineId";
+DROP TABLE "OrderLineDip";
+CREATE INDEX "IdxStoreItemDipOrderId" ON "StoreItemDip" ("OrderId");
+
+CREATE TABLE "StoreItemVariant" (
+    "LineId"  TEXT NOT NULL,
+    "OrderId" TEXT NOT NULL,
+    "Variant" TEXT NOT NULL,
+    PRIMARY KEY ("LineId", "Variant"),
+    FOREIGN KEY ("LineId") REFERENCES "OrderLine"("Id") ON DELETE CASCADE
+);
+INSERT INTO "StoreItemVariant" ("LineId", "OrderId", "Variant")
+SELECT v."LineId", l."OrderId", v."Variant"
+FROM "OrderLineVariant" v
+JOIN "OrderLine" l ON l."Id" = v."LineId";
+DROP TABLE "OrderLineVariant";
+CREATE INDEX "IdxStoreItemVariantOrderId" ON "StoreItemVariant" ("OrderId");
+
+DROP INDEX "IdxOrderLineOrderId";
+ALTER TABLE "OrderLine" RENAME TO "OrderItems";
+CREATE INDEX "IdxOrderItemsOrderId" ON "OrderItems" ("OrderId", "Position");
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE "Order" ADD COLUMN "Note" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "OrderLine" ADD COLUMN "Note" TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE "OrderLine" DROP COLUMN "Note";
+ALTER TABLE "Order" DROP COLUMN "Note";
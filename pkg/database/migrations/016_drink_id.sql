@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE "Drink" ADD COLUMN "MenuId" TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE "Drink" DROP COLUMN "MenuId";
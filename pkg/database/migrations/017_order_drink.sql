@@ -0,0 +1,62 @@
+-- +goose Up
+CREATE TABLE "OrderDrink" (
+    "Id"       TEXT NOT NULL PRIMARY KEY,
+    "OrderId"  TEXT NOT NULL,
+    "MenuId"   TEXT NOT NULL DEFAULT '',
+    "Name"     TEXT NOT NULL,
+    "Size"     INTEGER NOT NULL DEFAULT 0,
+    "Quantity" INTEGER NOT NULL DEFAULT 1,
+    "Position" INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY ("OrderId") REFERENCES "Order"("Id") ON DELETE CASCADE
+);
+CREATE INDEX "IdxOrderDrinkOrderId" ON "OrderDrink" ("OrderId", "Position");
+
+INSERT INTO "OrderDrink" ("Id", "OrderId", "MenuId", "Name", "Size")
+SELECT d."Id", o."Id", d."MenuId", d."Name", d."Size"
+FROM "Order" o
+JOIN "Drink" d ON d."Id" = o."DrinkId";
+
+-- The DrinkId column can not be dropped as long as it is referenced by
+-- a foreign key, so the table is rebuilt without it.
+CREATE TABLE "OrderNew" (
+    "Id"            TEXT NOT NULL PRIMARY KEY,
+    "Created"       TIMESTAMP NOT NULL,
+    "Creator"       TEXT NOT NULL,
+    "OrderListId"   TEXT NOT NULL,
+    "EditKey"       TEXT,
+    "Paid"          BOOLEAN NOT NULL DEFAULT 0,
+    "PaidAmount"    INTEGER NOT NULL DEFAULT 0,
+    "PaymentMethod" TEXT NOT NULL DEFAULT '',
+    "Note"          TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY ("OrderListId") REFERENCES "OrderList"("Id") ON DELETE CASCADE
+);
+INSERT INTO "OrderNew" ("Id", "Created", "Creator", "OrderListId", "EditKey", "Paid", "PaidAmount", "PaymentMethod", "Note")
+SELECT "Id", "Created", "Creator", "OrderListId", "EditKey", "Paid", "PaidAmount", "PaymentMethod", "Note"
+FROM "Order";
+DROP TABLE "Order";
+ALTER TABLE "OrderNew" RENAME TO "Order";
+CREATE INDEX "IdxOrderOrderListId" ON "Order" ("OrderListId");
+
+DROP TABLE "Drink";
+
+-- +goose Down
+CREATE TABLE "Drink" (
+    "Id"     TEXT NOT NULL PRIMARY KEY,
+    "Name"   TEXT NOT NULL,
+    "Size"   INTEGER NOT NULL,
+    "MenuId" TEXT NOT NULL DEFAULT ''
+);
+
+-- Only the first drink of each order is kept.
+INSERT INTO "Drink" ("Id", "Name", "Size", "MenuId")
+SELECT d."Id", d."Name", d."Size", d."MenuId"
+FROM "OrderDrink" d
+WHERE d."Position" = (SELECT MIN("Position") FROM "OrderDrink" WHERE "OrderId" = d."OrderId");
+
+ALTER TABLE "Order" ADD COLUMN "DrinkId" TEXT REFERENCES "Drink"("Id") ON DELETE CASCADE;
+UPDATE "Order" SET "DrinkId" = (
+    SELECT d."Id" FROM "OrderDrink" d
+    WHERE d."OrderId" = "Order"."Id"
+    ORDER BY d."Position" LIMIT 1);
+
+DROP TABLE "OrderDrink";
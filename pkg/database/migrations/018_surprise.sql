@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE "OrderLine" ADD COLUMN "SurpriseItemId" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "OrderLine" ADD COLUMN "SurpriseTitle" TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE "OrderLineSurpriseVariant" (
+    "LineId"  TEXT NOT NULL,
+    "Variant" TEXT NOT NULL,
+    PRIMARY KEY ("LineId", "Variant"),
+    FOREIGN KEY ("LineId") REFERENCES "OrderLine"("Id") ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE "OrderLineSurpriseVariant";
+ALTER TABLE "OrderLine" DROP COLUMN "SurpriseTitle";
+ALTER TABLE "OrderLine" DROP COLUMN "SurpriseItemId";
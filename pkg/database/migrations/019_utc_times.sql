@@ -0,0 +1,29 @@
+-- +goose Up
+-- Times are stored as text and only compare correctly with the same offset,
+-- so existing times are rewritten to UTC. The fractional seconds are kept
+-- as they were written.
+UPDATE "OrderList" SET "Created" = strftime('%Y-%m-%d %H:%M:%S', "Created")
+    || CASE WHEN substr("Created", 20, 1) = '.' THEN substr("Created", 20, length("Created") - 25) ELSE '' END || '+00:00'
+    WHERE "Created" GLOB '????-??-?? ??:??:??*[+-]??:??' AND "Created" NOT LIKE '%+00:00';
+UPDATE "OrderList" SET "Deadline" = strftime('%Y-%m-%d %H:%M:%S', "Deadline")
+    || CASE WHEN substr("Deadline", 20, 1) = '.' THEN substr("Deadline", 20, length("Deadline") - 25) ELSE '' END || '+00:00'
+    WHERE "Deadline" GLOB '????-??-?? ??:??:??*[+-]??:??' AND "Deadline" NOT LIKE '%+00:00';
+UPDATE "OrderList" SET "PickupTime" = strftime('%Y-%m-%d %H:%M:%S', "PickupTime")
+    || CASE WHEN substr("PickupTime", 20, 1) = '.' THEN substr("PickupTime", 20, length("PickupTime") - 25) ELSE '' END || '+00:00'
+    WHERE "PickupTime" GLOB '????-??-?? ??:??:??*[+-]??:??' AND "PickupTime" NOT LIKE '%+00:00';
+UPDATE "Order" SET "Created" = strftime('%Y-%m-%d %H:%M:%S', "Created")
+    || CASE WHEN substr("Created", 20, 1) = '.' THEN substr("Created", 20, length("Created") - 25) ELSE '' END || '+00:00'
+    WHERE "Created" GLOB '????-??-?? ??:??:??*[+-]??:??' AND "Created" NOT LIKE '%+00:00';
+UPDATE "Schedule" SET "Created" = strftime('%Y-%m-%d %H:%M:%S', "Created")
+    || CASE WHEN substr("Created", 20, 1) = '.' THEN substr("Created", 20, length("Created") - 25) ELSE '' END || '+00:00'
+    WHERE "Created" GLOB '????-??-?? ??:??:??*[+-]??:??' AND "Created" NOT LIKE '%+00:00';
+UPDATE "Schedule" SET "LastRun" = strftime('%Y-%m-%d %H:%M:%S', "LastRun")
+    || CASE WHEN substr("LastRun", 20, 1) = '.' THEN substr("LastRun", 20, length("LastRun") - 25) ELSE '' END || '+00:00'
+    WHERE "LastRun" GLOB '????-??-?? ??:??:??*[+-]??:??' AND "LastRun" NOT LIKE '%+00:00';
+UPDATE "Feedback" SET "Timestamp" = strftime('%Y-%m-%d %H:%M:%S', "Timestamp")
+    || CASE WHEN substr("Timestamp", 20, 1) = '.' THEN substr("Timestamp", 20, length("Timestamp") - 25) ELSE '' END || '+00:00'
+    WHERE "Timestamp" GLOB '????-??-?? ??:??:??*[+-]??:??' AND "Timestamp" NOT LIKE '%+00:00';
+
+-- +goose Down
+-- Times in UTC are valid for previous versions as well.
+SELECT 1;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Deleting lists and orders left their child rows behind, as foreign keys
+-- are not enforced.
+DELETE FROM "Order" WHERE "OrderListId" NOT IN (SELECT "Id" FROM "OrderList");
+DELETE FROM "OrderDrink" WHERE "OrderId" NOT IN (SELECT "Id" FROM "Order");
+DELETE FROM "OrderLine" WHERE "OrderId" NOT IN (SELECT "Id" FROM "Order");
+DELETE FROM "OrderLineVariant" WHERE "LineId" NOT IN (SELECT "Id" FROM "OrderLine");
+DELETE FROM "OrderLineDip" WHERE "LineId" NOT IN (SELECT "Id" FROM "OrderLine");
+DELETE FROM "OrderLineSurpriseVariant" WHERE "LineId" NOT IN (SELECT "Id" FROM "OrderLine");
+
+-- +goose Down
+SELECT 1;
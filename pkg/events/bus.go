@@ -0,0 +1,175 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	OrderCreated    Type = "order-created"
+	OrderUpdated    Type = "order-updated"
+	OrderDeleted    Type = "order-deleted"
+	DeadlineChanged Type = "deadline-changed"
+	StateChanged    Type = "state-changed"
+	ListDeleted     Type = "list-deleted"
+)
+
+// Event describes a mutation of an order list. Ids are assigned in
+// ascending order over all lists so that subscribers can resume
+// after the last event they have received.
+type Event struct {
+	Id     uint64    `json:"id"`
+	ListId string    `json:"list_id"`
+	Type   Type      `json:"type"`
+	Time   time.Time `json:"time"`
+	Data   any       `json:"data,omitempty"`
+}
+
+// Bus is an in-process publish/subscribe hub for order list events.
+// For each list, the latest events are kept in a bounded history so
+// that subscribers can catch up on events missed while reconnecting.
+type Bus struct {
+	mtx         sync.Mutex
+	lastId      uint64
+	history     map[string][]*Event
+	subs        map[string]map[*Subscription]struct{}
+	historySize int
+	bufferSize  int
+	retention   time.Duration
+}
+
+// NewBus creates a new Bus keeping up to historySize events per list
+// for at most retention. Each subscription buffers up to bufferSize
+// events; subscribers not keeping up are dropped.
+func NewBus(historySize, bufferSize int, retention time.Duration) *Bus {
+	return &Bus{
+		history:     make(map[string][]*Event),
+		subs:        make(map[string]map[*Subscription]struct{}),
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		retention:   retention,
+	}
+}
+
+// Publish sends an event of the given type to all subscribers of the
+// list. When a list is deleted, all of its subscriptions are closed
+// after the event has been delivered.
+func (t *Bus) Publish(listId string, typ Type, data any) *Event {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.lastId++
+	ev := &Event{
+		Id:     t.lastId,
+		ListId: listId,
+		Type:   typ,
+		Time:   time.Now(),
+		Data:   data,
+	}
+
+	for sub := range t.subs[listId] {
+		select {
+		case sub.c <- ev:
+		default:
+			// The subscriber does not keep up; dropping it prevents a
+			// single slow client from blocking all others.
+			t.unsubscribe(sub)
+		}
+	}
+
+	if typ == ListDeleted {
+		for sub := range t.subs[listId] {
+			t.unsubscribe(sub)
+		}
+		delete(t.history, listId)
+		return ev
+	}
+
+	hist := append(t.history[listId], ev)
+	if len(hist) > t.historySize {
+		hist = hist[len(hist)-t.historySize:]
+	}
+	t.history[listId] = hist
+	t.prune(ev.Time)
+
+	return ev
+}
+
+// Subscribe registers a subscription for events of the given list. If
+// lastEventId is not 0, all events of the list with a greater id which
+// are still in the history are returned as missed events. They precede
+// all events received on the subscription.
+func (t *Bus) Subscribe(listId string, lastEventId uint64) (sub *Subscription, missed []*Event) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if lastEventId != 0 {
+		for _, ev := range t.history[listId] {
+			if ev.Id > lastEventId {
+				missed = append(missed, ev)
+			}
+		}
+	}
+
+	sub = &Subscription{
+		bus:    t,
+		listId: listId,
+		c:      make(chan *Event, t.bufferSize),
+	}
+
+	if t.subs[listId] == nil {
+		t.subs[listId] = make(map[*Subscription]struct{})
+	}
+	t.subs[listId][sub] = struct{}{}
+
+	return sub, missed
+}
+
+func (t *Bus) unsubscribe(sub *Subscription) {
+	subs, ok := t.subs[sub.listId]
+	if !ok {
+		return
+	}
+	if _, ok = subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(t.subs, sub.listId)
+	}
+	close(sub.c)
+}
+
+// prune removes the histories of lists without events since retention.
+func (t *Bus) prune(now time.Time) {
+	for listId, hist := range t.history {
+		if now.Sub(hist[len(hist)-1].Time) > t.retention {
+			delete(t.history, listId)
+		}
+	}
+}
+
+// Subscription receives the events of a single order list.
+type Subscription struct {
+	bus    *Bus
+	listId string
+	c      chan *Event
+}
+
+// Events returns the channel events are received on. The channel is
+// closed when the subscription is closed, when the list has been
+// deleted or when the subscriber did not keep up with the events.
+func (t *Subscription) Events() <-chan *Event {
+	return t.c
+}
+
+// Close unregisters the subscription from the bus.
+func (t *Subscription) Close() {
+	t.bus.mtx.Lock()
+	defer t.bus.mtx.Unlock()
+
+	t.bus.unsubscribe(t)
+}
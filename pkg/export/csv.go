@@ -0,0 +1,48 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+// renderCSV writes one row per order. The last row contains the total
+// of the list.
+func renderCSV(w io.Writer, export *model.ListExport) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"Name", "Bestellung", "Getränke", "Notiz", "Summe", "Bezahlt"})
+	for _, order := range export.Orders {
+		cw.Write([]string{
+			csvText(order.Creator),
+			csvText(strings.Join(lineTexts(order), "\n")),
+			csvText(drinksText(order)),
+			csvText(order.Note),
+			formatTotal(order.TotalCents, order.TotalIncomplete),
+			paidText(order),
+		})
+	}
+	cw.Write([]string{
+		"Gesamt",
+		strconv.Itoa(len(export.Orders)) + " Bestellungen",
+		"",
+		"",
+		formatTotal(export.TotalCents, export.TotalIncomplete),
+		"",
+	})
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText prefixes text which spreadsheet applications would interpret
+// as formula with a quote, so that user input is always shown as text.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,10 @@
+package export
+
+import (
+	"github.com/studio-b12/elk"
+)
+
+const (
+	ErrUnsupportedFormat = elk.ErrorCode("export:unsupported-format")
+	ErrRender            = elk.ErrorCode("export:render")
+)
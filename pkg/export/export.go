@@ -0,0 +1,173 @@
+// Package export renders order lists into formats which can be copied
+// into chats or printed for the pickup.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/studio-b12/elk"
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+type Format string
+
+const (
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "md"
+	FormatPDF      Format = "pdf"
+	FormatText     Format = "txt"
+)
+
+type renderer func(w io.Writer, export *model.ListExport) error
+
+var renderers = map[Format]renderer{
+	FormatCSV:      renderCSV,
+	FormatMarkdown: renderMarkdown,
+	FormatPDF:      renderPDF,
+	FormatText:     renderText,
+}
+
+var contentTypes = map[Format]string{
+	FormatCSV:      "text/csv; charset=utf-8",
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatPDF:      "application/pdf",
+	FormatText:     "text/plain; charset=utf-8",
+}
+
+// ParseFormat returns the format with the given name, which is also the
+// file extension of the format.
+func ParseFormat(name string) (Format, error) {
+	format := Format(strings.ToLower(name))
+	if _, ok := renderers[format]; !ok {
+		return "", elk.NewErrorf(ErrUnsupportedFormat,
+			"unsupported export format %q; must be one of csv, md, pdf or txt", name)
+	}
+	return format, nil
+}
+
+// ContentType returns the MIME type of documents in this format.
+func (t Format) ContentType() string {
+	return contentTypes[t]
+}
+
+// Filename returns the name of the file the export of the given order
+// list is saved as.
+func Filename(export *model.ListExport, format Format) string {
+	id, _, _ := strings.Cut(export.List.Id, "-")
+	return fmt.Sprintf("hermans-%s-%s.%s", export.List.Created.Format("2006-01-02"), id, format)
+}
+
+// Render writes the order list export in the given format to w.
+func Render(w io.Writer, format Format, export *model.ListExport) error {
+	render, ok := renderers[format]
+	if !ok {
+		return elk.NewErrorf(ErrUnsupportedFormat, "unsupported export format %q", format)
+	}
+	if err := render(w, export); err != nil {
+		return elk.Wrap(ErrRender, err, "failed rendering export")
+	}
+	return nil
+}
+
+func title(list *model.OrderList) string {
+	if list.Title != "" {
+		return list.Title
+	}
+	return "Bestellliste vom " + list.Created.Local().Format("02.01.2006")
+}
+
+// details returns the labeled properties of the order list which are
+// set, in the order they are rendered in.
+func details(list *model.OrderList) (lines [][2]string) {
+	if list.Organizer != "" {
+		lines = append(lines, [2]string{"Organisiert von", list.Organizer})
+	}
+	if list.Deadline != nil {
+		lines = append(lines, [2]string{"Bestellschluss", formatTime(*list.Deadline)})
+	}
+	if list.PickupTime != nil {
+		lines = append(lines, [2]string{"Abholung", formatTime(*list.PickupTime)})
+	}
+	if list.PickupLocation != "" {
+		lines = append(lines, [2]string{"Abholort", list.PickupLocation})
+	}
+	if list.Description != "" {
+		lines = append(lines, [2]string{"Beschreibung", list.Description})
+	}
+	return lines
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("02.01.2006 15:04")
+}
+
+// formatTotal formats a total in cents as Euro amount. Incomplete totals
+// are the lower bound of the actual total.
+func formatTotal(cents int, incomplete bool) string {
+	s := fmt.Sprintf("%d,%02d €", cents/100, cents%100)
+	if incomplete {
+		s = "mind. " + s
+	}
+	return s
+}
+
+// lineText describes an order line, e.g.
+// "2x Classic (Käse, Salat; Dip: Ketchup) – ohne Zwiebeln".
+func lineText(line *model.ExportLine) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%dx %s", line.Quantity, line.Title)
+
+	var extras []string
+	if len(line.Variants) != 0 {
+		extras = append(extras, strings.Join(line.Variants, ", "))
+	}
+	if len(line.Dips) != 0 {
+		extras = append(extras, "Dip: "+strings.Join(line.Dips, ", "))
+	}
+	if len(extras) != 0 {
+		fmt.Fprintf(&sb, " (%s)", strings.Join(extras, "; "))
+	}
+
+	if line.Note != "" {
+		sb.WriteString(" – " + line.Note)
+	}
+	if line.Surprise {
+		sb.WriteString(" [Überraschung]")
+	}
+	if line.Unavailable {
+		sb.WriteString(" [nicht mehr auf der Karte]")
+	}
+	return sb.String()
+}
+
+// drinksText lists the drinks of an order, e.g.
+// "2x Wasser, 1x Coca-Cola (0,5 l)".
+func drinksText(order *model.ExportOrder) string {
+	texts := make([]string, 0, len(order.Drinks))
+	for _, drink := range order.Drinks {
+		text := fmt.Sprintf("%dx %s", drink.Quantity, drink.Name)
+		if drink.Size != "" {
+			text += " (" + drink.Size + ")"
+		}
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, ", ")
+}
+
+func paidText(order *model.ExportOrder) string {
+	if order.Paid {
+		return "bezahlt"
+	}
+	return "offen"
+}
+
+func lineTexts(order *model.ExportOrder) []string {
+	texts := make([]string, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		texts = append(texts, lineText(line))
+	}
+	return texts
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", `\<`, "\r", "", "\n", " ")
+
+// renderMarkdown writes the list details followed by a table containing
+// one row per order.
+func renderMarkdown(w io.Writer, export *model.ListExport) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# %s\n\n", markdownEscaper.Replace(title(export.List)))
+	if d := details(export.List); len(d) != 0 {
+		for _, detail := range d {
+			fmt.Fprintf(bw, "- **%s:** %s\n", detail[0], markdownEscaper.Replace(detail[1]))
+		}
+		fmt.Fprintln(bw)
+	}
+
+	fmt.Fprintln(bw, "| Name | Bestellung | Getränke | Notiz | Summe | Bezahlt |")
+	fmt.Fprintln(bw, "|---|---|---|---|---:|---|")
+	for _, order := range export.Orders {
+		lines := lineTexts(order)
+		for i, line := range lines {
+			lines[i] = markdownEscaper.Replace(line)
+		}
+		fmt.Fprintf(bw, "| %s | %s | %s | %s | %s | %s |\n",
+			markdownEscaper.Replace(order.Creator),
+			strings.Join(lines, "<br>"),
+			markdownEscaper.Replace(drinksText(order)),
+			markdownEscaper.Replace(order.Note),
+			formatTotal(order.TotalCents, order.TotalIncomplete),
+			paidText(order))
+	}
+
+	fmt.Fprintf(bw, "\n**Gesamt:** %s (%d Bestellungen)\n",
+		formatTotal(export.TotalCents, export.TotalIncomplete), len(export.Orders))
+
+	return bw.Flush()
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+const (
+	pdfFont       = "Helvetica"
+	pdfLineHeight = 5.5
+	pdfIndent     = 6
+)
+
+var pdfSpacesRx = regexp.MustCompile(`[ \t]{2,}`)
+
+// renderPDF writes the list as A4 document for printing. The core fonts
+// of PDF only support the cp1252 code page, so all text is translated
+// and characters which are not part of it, like emojis, are removed.
+func renderPDF(w io.Writer, export *model.ListExport) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+
+	translate := pdf.UnicodeTranslatorFromDescriptor("cp1252")
+	tr := func(s string) string {
+		return translate(pdfText(translate, s))
+	}
+
+	pdf.SetTitle(tr(title(export.List)), false)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Seite %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont(pdfFont, "B", 18)
+	pdf.MultiCell(0, 9, tr(title(export.List)), "", "L", false)
+	pdf.Ln(2)
+
+	pdf.SetFont(pdfFont, "", 11)
+	for _, detail := range details(export.List) {
+		pdf.MultiCell(0, pdfLineHeight, tr(detail[0]+": "+detail[1]), "", "L", false)
+	}
+
+	left, _, _, _ := pdf.GetMargins()
+	indented := func(style, text string) {
+		pdf.SetFont(pdfFont, style, 11)
+		pdf.SetX(left + pdfIndent)
+		pdf.MultiCell(0, pdfLineHeight, tr(text), "", "L", false)
+	}
+
+	for _, order := range export.Orders {
+		pdf.Ln(4)
+		pdf.SetFont(pdfFont, "B", 12)
+		pdf.MultiCell(0, 6.5, tr(fmt.Sprintf("%s – %s (%s)", order.Creator,
+			formatTotal(order.TotalCents, order.TotalIncomplete), paidText(order))), "", "L", false)
+
+		for _, text := range lineTexts(order) {
+			indented("", text)
+		}
+		if len(order.Drinks) != 0 {
+			indented("", "Getränke: "+drinksText(order))
+		}
+		if order.Note != "" {
+			indented("I", "Notiz: "+order.Note)
+		}
+	}
+
+	pdf.Ln(6)
+	pdf.SetFont(pdfFont, "B", 12)
+	pdf.MultiCell(0, 6.5, tr(fmt.Sprintf("Gesamt: %s (%d Bestellungen)",
+		formatTotal(export.TotalCents, export.TotalIncomplete), len(export.Orders))), "", "L", false)
+
+	return pdf.Output(w)
+}
+
+// pdfText removes all characters from s which can not be translated into
+// the code page of translate.
+func pdfText(translate func(string) string, s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r != '.' && translate(string(r)) == "." {
+			return -1
+		}
+		return r
+	}, s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(pdfSpacesRx.ReplaceAllString(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/zekrotja/hermans/pkg/model"
+)
+
+// renderText writes the list as plain text which can be pasted into
+// chats without any formatting.
+func renderText(w io.Writer, export *model.ListExport) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, title(export.List))
+	for _, detail := range details(export.List) {
+		fmt.Fprintf(bw, "%s: %s\n", detail[0], detail[1])
+	}
+
+	for _, order := range export.Orders {
+		fmt.Fprintf(bw, "\n%s – %s (%s)\n", order.Creator,
+			formatTotal(order.TotalCents, order.TotalIncomplete), paidText(order))
+		for _, text := range lineTexts(order) {
+			fmt.Fprintf(bw, "  %s\n", text)
+		}
+		if len(order.Drinks) != 0 {
+			fmt.Fprintf(bw, "  Getränke: %s\n", drinksText(order))
+		}
+		if order.Note != "" {
+			fmt.Fprintf(bw, "  Notiz: %s\n", order.Note)
+		}
+	}
+
+	fmt.Fprintf(bw, "\nGesamt: %s (%d Bestellungen)\n",
+		formatTotal(export.TotalCents, export.TotalIncomplete), len(export.Orders))
+
+	return bw.Flush()
+}
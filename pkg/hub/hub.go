@@ -0,0 +1,237 @@
+package hub
+
+import (
+	"cmp"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zekrotja/hermans/pkg/events"
+)
+
+// EventSource provides the mutation events of order lists.
+type EventSource interface {
+	SubscribeListEvents(orderListId string, lastEventId uint64) (*events.Subscription, []*events.Event, error)
+}
+
+// typingInterval is the minimum interval in which typing messages of a
+// client are relayed to the other clients.
+const typingInterval = time.Second
+
+// Hub tracks the clients connected to order lists. It forwards the
+// mutation events of a list to all of its clients and keeps them
+// informed about who else is currently connected and choosing.
+//
+// Messages are delivered via a bounded send buffer per client. Clients
+// whose buffer is full are disconnected so that a slow client can not
+// block the others.
+type Hub struct {
+	mtx        sync.Mutex
+	events     EventSource
+	rooms      map[string]*room
+	bufferSize int
+}
+
+type room struct {
+	listId   string
+	clients  map[*Client]struct{}
+	eventSub *events.Subscription
+}
+
+// Client is a single connection to a list.
+type Client struct {
+	Id       string
+	Name     string
+	Choosing bool
+
+	room       *room
+	send       chan []byte
+	lastTyping time.Time
+}
+
+// Send returns the channel of encoded messages to be written to the
+// client. The channel is closed when the client has been removed
+// from the hub.
+func (t *Client) Send() <-chan []byte {
+	return t.send
+}
+
+func New(events EventSource, bufferSize int) *Hub {
+	return &Hub{
+		events:     events,
+		rooms:      make(map[string]*room),
+		bufferSize: bufferSize,
+	}
+}
+
+// Join adds a new client with the given display name to the list. An
+// error is returned if the events of the list can not be subscribed,
+// for example because the list does not exist.
+func (t *Hub) Join(listId, name string) (*Client, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	r, ok := t.rooms[listId]
+	if !ok {
+		sub, _, err := t.events.SubscribeListEvents(listId, 0)
+		if err != nil {
+			return nil, err
+		}
+		r = &room{
+			listId:   listId,
+			clients:  make(map[*Client]struct{}),
+			eventSub: sub,
+		}
+		t.rooms[listId] = r
+		go t.forwardEvents(r)
+	}
+
+	c := &Client{
+		Id:   uuid.New().String(),
+		Name: name,
+		room: r,
+		send: make(chan []byte, t.bufferSize),
+	}
+	r.clients[c] = struct{}{}
+
+	t.sendTo(c, Message{Type: MessageTypeWelcome, Client: clientInfoOf(c)})
+	t.broadcastPresence(r)
+
+	return c, nil
+}
+
+// Leave removes the client from its list. It is safe to call Leave
+// for clients which have already been removed.
+func (t *Hub) Leave(c *Client) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.remove(c)
+}
+
+// HandleMessage processes a message received from the client. Typing
+// messages are relayed at most once per typingInterval and presence is
+// only broadcasted when the choosing state of the client changes.
+func (t *Hub) HandleMessage(c *Client, msg *ClientMessage) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if _, ok := c.room.clients[c]; !ok {
+		return
+	}
+
+	switch msg.Type {
+	case MessageTypeChoosing:
+		if c.Choosing == msg.Choosing {
+			return
+		}
+		c.Choosing = msg.Choosing
+		t.broadcastPresence(c.room)
+	case MessageTypeTyping:
+		now := time.Now()
+		if now.Sub(c.lastTyping) < typingInterval {
+			return
+		}
+		c.lastTyping = now
+		t.broadcast(c.room, Message{Type: MessageTypeTyping, Client: clientInfoOf(c)}, c)
+	}
+}
+
+func (t *Hub) forwardEvents(r *room) {
+	for ev := range r.eventSub.Events() {
+		t.mtx.Lock()
+		t.broadcast(r, Message{Type: MessageTypeEvent, Event: ev}, nil)
+		t.mtx.Unlock()
+	}
+
+	// The subscription is closed when the list has been deleted, when
+	// the last client left or when this room did not keep up with the
+	// events. Remaining clients are disconnected so that they reconnect
+	// and receive a consistent state.
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for c := range r.clients {
+		t.remove(c)
+	}
+}
+
+func (t *Hub) remove(c *Client) {
+	r := c.room
+	if _, ok := r.clients[c]; !ok {
+		return
+	}
+
+	delete(r.clients, c)
+	close(c.send)
+
+	if len(r.clients) == 0 {
+		if t.rooms[r.listId] == r {
+			delete(t.rooms, r.listId)
+		}
+		r.eventSub.Close()
+		return
+	}
+
+	t.broadcastPresence(r)
+}
+
+func (t *Hub) broadcastPresence(r *room) {
+	presence := &Presence{
+		Connected: len(r.clients),
+		Clients:   make([]*ClientInfo, 0, len(r.clients)),
+	}
+	for c := range r.clients {
+		if c.Choosing {
+			presence.Choosing++
+		}
+		presence.Clients = append(presence.Clients, clientInfoOf(c))
+	}
+	slices.SortFunc(presence.Clients, func(a, b *ClientInfo) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.Id, b.Id))
+	})
+
+	t.broadcast(r, Message{Type: MessageTypePresence, Presence: presence}, nil)
+}
+
+func (t *Hub) broadcast(r *room, msg Message, except *Client) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("failed encoding hub message", "err", err)
+		return
+	}
+
+	var slow []*Client
+	for c := range r.clients {
+		if c == except {
+			continue
+		}
+		select {
+		case c.send <- data:
+		default:
+			slow = append(slow, c)
+		}
+	}
+
+	for _, c := range slow {
+		slog.Debug("disconnecting slow hub client", "list", r.listId, "client", c.Id)
+		t.remove(c)
+	}
+}
+
+func (t *Hub) sendTo(c *Client, msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("failed encoding hub message", "err", err)
+		return
+	}
+
+	select {
+	case c.send <- data:
+	default:
+		t.remove(c)
+	}
+}
@@ -0,0 +1,60 @@
+package hub
+
+import "github.com/zekrotja/hermans/pkg/events"
+
+type MessageType string
+
+const (
+	// MessageTypeWelcome is sent to a client after joining and contains
+	// its own client info.
+	MessageTypeWelcome MessageType = "welcome"
+	// MessageTypeEvent carries a mutation event of the list.
+	MessageTypeEvent MessageType = "event"
+	// MessageTypePresence is sent whenever a client joins, leaves or
+	// changes its choosing state.
+	MessageTypePresence MessageType = "presence"
+	// MessageTypeChoosing is sent by clients to set their choosing state.
+	MessageTypeChoosing MessageType = "choosing"
+	// MessageTypeTyping is sent by clients while typing and relayed to
+	// all other clients of the list.
+	MessageTypeTyping MessageType = "typing"
+)
+
+type Message struct {
+	Type MessageType `json:"type"`
+
+	// Set on event messages.
+	Event *events.Event `json:"event,omitempty"`
+
+	// Set on presence messages.
+	Presence *Presence `json:"presence,omitempty"`
+
+	// Set on welcome and typing messages.
+	Client *ClientInfo `json:"client,omitempty"`
+}
+
+type Presence struct {
+	Connected int           `json:"connected"`
+	Choosing  int           `json:"choosing"`
+	Clients   []*ClientInfo `json:"clients"`
+}
+
+// ClientMessage is a message sent by a client.
+type ClientMessage struct {
+	Type     MessageType `json:"type"`
+	Choosing bool        `json:"choosing"`
+}
+
+type ClientInfo struct {
+	Id       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Choosing bool   `json:"choosing"`
+}
+
+func clientInfoOf(c *Client) *ClientInfo {
+	return &ClientInfo{
+		Id:       c.Id,
+		Name:     c.Name,
+		Choosing: c.Choosing,
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+// ListExport is an order list prepared for being printed or copied
+// somewhere else. Store item IDs and variant names of the orders are
+// resolved to their titles on the current menu.
+type ListExport struct {
+	List            *OrderList     `json:"list"`
+	Orders          []*ExportOrder `json:"orders"`
+	TotalCents      int            `json:"total_cents"`
+	TotalIncomplete bool           `json:"total_incomplete,omitempty"`
+}
+
+type ExportOrder struct {
+	Creator         string         `json:"creator"`
+	Lines           []*ExportLine  `json:"lines"`
+	Drinks          []*ExportDrink `json:"drinks,omitempty"`
+	Note            string         `json:"note,omitempty"`
+	Paid            bool           `json:"paid"`
+	TotalCents      int            `json:"total_cents"`
+	TotalIncomplete bool           `json:"total_incomplete,omitempty"`
+}
+
+// ExportLine is an order line. Resolved surprise lines are exported as
+// the dish they were resolved to and flagged as Surprise.
+type ExportLine struct {
+	Title       string   `json:"title"`
+	Quantity    int      `json:"quantity"`
+	Variants    []string `json:"variants,omitempty"`
+	Dips        []string `json:"dips,omitempty"`
+	Note        string   `json:"note,omitempty"`
+	Surprise    bool     `json:"surprise,omitempty"`
+	Unavailable bool     `json:"unavailable,omitempty"`
+}
+
+// ExportDrink is an ordered drink. Size is the name of the size on the
+// menu, which is empty if the drink is only available in one size.
+type ExportDrink struct {
+	Name     string `json:"name"`
+	Size     string `json:"size,omitempty"`
+	Quantity int    `json:"quantity"`
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+type ListStatus string
+
+const (
+	// ListStatusOpen matches lists which are open and whose deadline,
+	// if any, has not been exceeded yet.
+	ListStatusOpen ListStatus = "open"
+	// ListStatusClosed matches all lists which are not open.
+	ListStatusClosed ListStatus = "closed"
+)
+
+type ListSortField string
+
+const (
+	ListSortCreated  ListSortField = "created"
+	ListSortDeadline ListSortField = "deadline"
+)
+
+// ListFilter selects and orders the order lists returned by a listing.
+// Lists without a deadline are sorted after all lists with one. After is
+// the cursor of the last list of the previous page.
+type ListFilter struct {
+	Status        ListStatus    `validate:"omitempty,oneof=open closed"`
+	CreatedAfter  *time.Time    `validate:"-"`
+	CreatedBefore *time.Time    `validate:"-"`
+	HasDeadline   *bool         `validate:"-"`
+	SortBy        ListSortField `validate:"oneof=created deadline"`
+	Descending    bool          `validate:"-"`
+	Limit         int           `validate:"min=1,max=100"`
+	After         *ListCursor   `validate:"-"`
+}
+
+// ListCursor points to the position of an order list in a listing.
+type ListCursor struct {
+	Value time.Time
+	Id    string
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ParseListCursor decodes a cursor previously encoded by String.
+func ParseListCursor(s string) (*ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	value, id, ok := strings.Cut(string(data), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &ListCursor{Value: t, Id: id}, nil
+}
+
+func (t ListCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(t.Value.Format(time.RFC3339Nano) + "|" + t.Id))
+}
+
+// OrderListSummary is an order list as shown in listings.
+type OrderListSummary struct {
+	Id      string    `json:"id"`
+	Created time.Time `json:"created"`
+	ListInfo
+	Deadline   *time.Time `json:"deadline,omitempty"`
+	State      ListState  `json:"state"`
+	ScheduleId string     `json:"schedule_id,omitempty"`
+	OrderCount int        `json:"order_count"`
+}
+
+// CursorValue returns the value the summary is sorted by.
+func (t *OrderListSummary) CursorValue(sortBy ListSortField) time.Time {
+	if sortBy == ListSortDeadline {
+		if t.Deadline == nil {
+			return NoDeadline
+		}
+		return *t.Deadline
+	}
+	return t.Created
+}
+
+// NoDeadline is the deadline lists without one are sorted by.
+var NoDeadline = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
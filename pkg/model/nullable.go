@@ -0,0 +1,29 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Nullable is a value of a patch payload which distinguishes between
+// a field not being present at all and a field being explicitly set
+// to null.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (t *Nullable[T]) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	if bytes.Equal(data, []byte("null")) {
+		t.Value = nil
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	t.Value = &v
+	return nil
+}
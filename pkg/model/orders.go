@@ -1,39 +1,178 @@
 package model
 
 import (
+	"slices"
 	"time"
 )
 
+// DrinkSize is the index of a size in the sizes of a drink on the menu,
+// where 0 is the first size.
 type DrinkSize int
 
+// ListState is the state of an order list. Orders can only be created,
+// changed and deleted while the list is open.
+type ListState string
+
 const (
-	DrinkSizeSmall DrinkSize = 0
-	DrinkSizeLarge DrinkSize = 1
+	ListStateOpen      ListState = "open"
+	ListStateLocked    ListState = "locked"
+	ListStateOrdered   ListState = "ordered"
+	ListStateDelivered ListState = "delivered"
+	ListStateCancelled ListState = "cancelled"
+)
+
+var listStateTransitions = map[ListState][]ListState{
+	ListStateOpen:    {ListStateLocked, ListStateCancelled},
+	ListStateLocked:  {ListStateOpen, ListStateOrdered, ListStateCancelled},
+	ListStateOrdered: {ListStateDelivered, ListStateCancelled},
+}
+
+// CanTransitionTo returns true if a list in this state may be moved to
+// the next state.
+func (t ListState) CanTransitionTo(next ListState) bool {
+	return slices.Contains(listStateTransitions[t], next)
+}
+
+type PaymentMethod string
+
+const (
+	PaymentMethodCash     PaymentMethod = "cash"
+	PaymentMethodPayPal   PaymentMethod = "paypal"
+	PaymentMethodTransfer PaymentMethod = "transfer"
+	PaymentMethodOther    PaymentMethod = "other"
 )
 
 type OrderList struct {
-	Id       string     `json:"id"`
-	Created  time.Time  `json:"created"`
-	Orders   []*Order   `json:"orders"`
-	Deadline *time.Time `json:"deadline,omitempty"`
+	Id      string    `json:"id"`
+	Created time.Time `json:"created"`
+	ListInfo
+	Orders        []*Order   `json:"orders"`
+	Deadline      *time.Time `json:"deadline,omitempty"`
+	State         ListState  `json:"state"`
+	ScheduleId    string     `json:"schedule_id,omitempty"`
+	ManagementKey string     `json:"-"`
+}
+
+// ListInfo describes an order list to the people ordering on it.
+type ListInfo struct {
+	Title          string     `json:"title" validate:"max=100"`
+	Description    string     `json:"description" validate:"max=1000"`
+	Organizer      string     `json:"organizer" validate:"max=64"`
+	PickupTime     *time.Time `json:"pickup_time,omitempty"`
+	PickupLocation string     `json:"pickup_location" validate:"max=200"`
 }
 
+// StoreItem is a line of an order. The same store item can be ordered
+// on multiple lines with different variants, dips and notes. A quantity
+// of 0 is treated as 1. Surprise is only set on lines of the surprise
+// item once the orderer resolved them to a dish, which then applies to
+// the whole quantity of the line.
 type StoreItem struct {
-	Id       string   `json:"id" validate:"required"`
-	Variants []string `json:"variants" validate:"unique"`
-	Dips     []string `json:"dips" validate:"unique"`
+	LineId   string    `json:"line_id"`
+	Id       string    `json:"id" validate:"required"`
+	Quantity int       `json:"quantity" validate:"gte=0,lte=99"`
+	Variants []string  `json:"variants" validate:"unique"`
+	Dips     []string  `json:"dips" validate:"unique"`
+	Note     string    `json:"note,omitempty" validate:"max=200"`
+	Surprise *Surprise `json:"surprise,omitempty" validate:"-"`
+}
+
+// Dish returns the store item ID and variants of the dish ordered on the
+// line, which is the dish a surprise line was resolved to.
+func (t *StoreItem) Dish() (id string, variants []string) {
+	if t.Surprise != nil {
+		return t.Surprise.Id, t.Surprise.Variants
+	}
+	return t.Id, t.Variants
 }
 
+// Surprise is the dish of the menu a surprise line was resolved to.
+// Variants are the variants of the dish picked to honor the variants
+// of the surprise line.
+type Surprise struct {
+	Id       string   `json:"id"`
+	Title    string   `json:"title"`
+	Variants []string `json:"variants,omitempty"`
+}
+
+// Drink is a drink line of an order referencing a drink of the menu by
+// its ID. Name is set to the name of the drink on the menu when the order
+// is placed. Orders placed before drinks had IDs only have a name. A
+// quantity of 0 is treated as 1.
 type Drink struct {
-	Name string    `json:"name" validate:"required"`
-	Size DrinkSize `json:"size" validate:"gte=0,lte=1"`
+	Id       string    `json:"id" validate:"required"`
+	Name     string    `json:"name"`
+	Size     DrinkSize `json:"size" validate:"gte=0"`
+	Quantity int       `json:"quantity" validate:"gte=0,lte=99"`
 }
 
 type Order struct {
-	Id         string       `json:"id"`
-	Created    time.Time    `json:"created"`
-	Creator    string       `json:"creator" validate:"required"`
-	StoreItems []*StoreItem `json:"store_items" validate:"required,min=1"`
-	Drink      *Drink       `json:"drink"`
-	EditKey    string       `json:"-"`
+	Id              string       `json:"id"`
+	Created         time.Time    `json:"created"`
+	Creator         string       `json:"creator" validate:"required"`
+	StoreItems      []*StoreItem `json:"store_items" validate:"required,min=1,dive,required"`
+	Drinks          []*Drink     `json:"drinks" validate:"dive,required"`
+	Drink           *Drink       `json:"drink" validate:"-"` // Deprecated: first drink line for clients not supporting Drinks yet.
+	Note            string       `json:"note,omitempty" validate:"max=500"`
+	EditKey         string       `json:"-"`
+	Payment         Payment      `json:"payment"`
+	TotalCents      int          `json:"total_cents"`
+	TotalIncomplete bool         `json:"total_incomplete,omitempty"`
+}
+
+// SyncLegacyDrink keeps the deprecated Drink field in sync with Drinks.
+// If the drink lines are not given at all, Drink becomes the only drink
+// line of the order. Afterwards, Drink is set to the first drink line.
+func (t *Order) SyncLegacyDrink() {
+	if t.Drinks == nil {
+		t.Drinks = []*Drink{}
+		if t.Drink != nil {
+			t.Drinks = append(t.Drinks, t.Drink)
+		}
+	}
+	t.Drink = nil
+	if len(t.Drinks) != 0 {
+		t.Drink = t.Drinks[0]
+	}
+}
+
+// Payment tracks whether the creator of an order has paid back the
+// person who paid the café.
+type Payment struct {
+	Paid      bool          `json:"paid"`
+	PaidCents int           `json:"paid_cents" validate:"gte=0"`
+	Method    PaymentMethod `json:"method,omitempty" validate:"omitempty,oneof=cash paypal transfer other"`
+}
+
+type PaymentSummary struct {
+	PaidCents        int `json:"paid_cents"`
+	OutstandingCents int `json:"outstanding_cents"`
+	PaidOrders       int `json:"paid_orders"`
+	UnpaidOrders     int `json:"unpaid_orders"`
+}
+
+// SummarizePayments sums up the paid and outstanding amounts of the given
+// orders. The outstanding amount of an unpaid order is its total minus
+// the amount already paid.
+func SummarizePayments(orders []*Order) (summary PaymentSummary) {
+	for _, order := range orders {
+		summary.PaidCents += order.Payment.PaidCents
+		if order.Payment.Paid {
+			summary.PaidOrders++
+			continue
+		}
+		summary.UnpaidOrders++
+		summary.OutstandingCents += max(order.TotalCents-order.Payment.PaidCents, 0)
+	}
+	return summary
+}
+
+// SumTotals returns the sum of the totals of the given orders. incomplete
+// is true if the total of any of the orders is incomplete.
+func SumTotals(orders []*Order) (totalCents int, incomplete bool) {
+	for _, order := range orders {
+		totalCents += order.TotalCents
+		incomplete = incomplete || order.TotalIncomplete
+	}
+	return totalCents, incomplete
 }
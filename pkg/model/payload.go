@@ -3,14 +3,49 @@ package model
 import "time"
 
 type CreateListPayload struct {
+	ListInfo
 	Deadline *time.Time `json:"deadline"`
 }
 
+// UpdateListPayload changes the properties of an order list. Fields
+// which are not present are left unchanged.
+type UpdateListPayload struct {
+	Deadline      Nullable[time.Time] `json:"deadline"`
+	ManagementKey string              `json:"managementKey"`
+}
+
+type UpdateListStatePayload struct {
+	State         ListState `json:"state"`
+	ManagementKey string    `json:"managementKey"`
+}
+
+type DeleteListPayload struct {
+	ManagementKey string `json:"managementKey"`
+}
+
 type UpdateOrderPayload struct {
 	Order
-	EditKey string `json:"editKey"`
+	EditKey       string `json:"editKey"`
+	ManagementKey string `json:"managementKey"`
+}
+
+type DeleteSchedulePayload struct {
+	ManagementKey string `json:"managementKey"`
 }
 
 type DeleteOrderPayload struct {
-	EditKey string `json:"editKey"`
+	EditKey       string `json:"editKey"`
+	ManagementKey string `json:"managementKey"`
+}
+
+// ResolveSurprisesPayload requests resolving the surprise lines of a
+// list. If Seed is given, already resolved lines are resolved again.
+type ResolveSurprisesPayload struct {
+	Seed          *uint64 `json:"seed"`
+	ManagementKey string  `json:"managementKey"`
+}
+
+type UpdatePaymentPayload struct {
+	Payment
+	ManagementKey string `json:"managementKey"`
 }
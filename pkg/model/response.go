@@ -7,13 +7,40 @@ type CreateOrderResponse struct {
 	Created    time.Time    `json:"created"`
 	Creator    string       `json:"creator"`
 	StoreItems []*StoreItem `json:"store_items"`
+	Drinks     []*Drink     `json:"drinks"`
 	Drink      *Drink       `json:"drink"`
+	Note       string       `json:"note,omitempty"`
 	EditKey    string       `json:"editKey"`
 }
 
+type CreateOrderListResponse struct {
+	Id      string    `json:"id"`
+	Created time.Time `json:"created"`
+	ListInfo
+	Deadline      *time.Time `json:"deadline"`
+	State         ListState  `json:"state"`
+	ManagementKey string     `json:"managementKey"`
+}
+
+type CreateScheduleResponse struct {
+	Schedule
+	ManagementKey string `json:"managementKey"`
+}
+
+type ListOrderListsResponse struct {
+	Lists      []*OrderListSummary `json:"lists"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
 type GetOrderListResponse struct {
-	Id       string     `json:"id"`
-	Created  time.Time  `json:"created"`
-	Deadline *time.Time `json:"deadline"`
-	Orders   []*Order   `json:"orders"`
+	Id      string    `json:"id"`
+	Created time.Time `json:"created"`
+	ListInfo
+	Deadline        *time.Time     `json:"deadline"`
+	State           ListState      `json:"state"`
+	ScheduleId      string         `json:"schedule_id,omitempty"`
+	Orders          []*Order       `json:"orders"`
+	TotalCents      int            `json:"total_cents"`
+	TotalIncomplete bool           `json:"total_incomplete,omitempty"`
+	Payments        PaymentSummary `json:"payments"`
 }
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Schedule is a template from which order lists are created
+// periodically. Cron is a standard five field cron expression or
+// descriptor like "@weekly" which can be prefixed with "CRON_TZ=<zone>".
+// Lists created from the schedule get a deadline DeadlineOffsetMinutes
+// after their creation unless the offset is 0.
+type Schedule struct {
+	Id                    string     `json:"id"`
+	Created               time.Time  `json:"created"`
+	Title                 string     `json:"title" validate:"required,max=100"`
+	Cron                  string     `json:"cron" validate:"required"`
+	DeadlineOffsetMinutes int        `json:"deadline_offset_minutes" validate:"gte=0"`
+	LastRun               *time.Time `json:"last_run,omitempty"`
+	NextRun               *time.Time `json:"next_run,omitempty"`
+	ManagementKey         string     `json:"-"`
+}
+
+// DeadlineOffset returns the deadline offset of lists created from the
+// schedule.
+func (t *Schedule) DeadlineOffset() time.Duration {
+	return time.Duration(t.DeadlineOffsetMinutes) * time.Minute
+}
@@ -0,0 +1,56 @@
+package model
+
+// ListSummary is the consolidated order of a list as it is placed at the
+// shop. Identical store items with the same variants and dips are
+// grouped over all orders. Lines referencing store items, variants, dips
+// or drinks which are no longer on the menu are flagged as unavailable.
+type ListSummary struct {
+	Id              string          `json:"id"`
+	Title           string          `json:"title,omitempty"`
+	OrderCount      int             `json:"order_count"`
+	Items           []*SummaryItem  `json:"items"`
+	Drinks          []*SummaryDrink `json:"drinks"`
+	Notes           []*SummaryNote  `json:"notes,omitempty"`
+	TotalCents      int             `json:"total_cents"`
+	TotalIncomplete bool            `json:"total_incomplete,omitempty"`
+	HasUnavailable  bool            `json:"has_unavailable,omitempty"`
+}
+
+// SummaryItem is a store item ordered with the same variants and dips
+// over all orders. Surprises is the part of Count ordered as surprise
+// and resolved to this item. Notes holds the notes of the grouped lines
+// along with who gave them.
+type SummaryItem struct {
+	StoreItemId string            `json:"store_item_id"`
+	Title       string            `json:"title"`
+	Category    string            `json:"category,omitempty"`
+	Variants    []*SummaryVariant `json:"variants,omitempty"`
+	Dips        []string          `json:"dips,omitempty"`
+	Count       int               `json:"count"`
+	Surprises   int               `json:"surprises,omitempty"`
+	Creators    []string          `json:"creators"`
+	Notes       []*SummaryNote    `json:"notes,omitempty"`
+	Unavailable bool              `json:"unavailable,omitempty"`
+}
+
+type SummaryVariant struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+}
+
+type SummaryDrink struct {
+	Id          string    `json:"id,omitempty"`
+	Name        string    `json:"name"`
+	Size        DrinkSize `json:"size"`
+	SizeName    string    `json:"size_name,omitempty"`
+	Count       int       `json:"count"`
+	Creators    []string  `json:"creators"`
+	Unavailable bool      `json:"unavailable,omitempty"`
+}
+
+// SummaryNote is a note given for an order as a whole or for one of its
+// lines.
+type SummaryNote struct {
+	Creator string `json:"creator"`
+	Note    string `json:"note"`
+}
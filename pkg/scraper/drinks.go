@@ -0,0 +1,146 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// volumeRx matches drink volumes like "0,3 l", "0.5l" or "330 ml".
+var volumeRx = regexp.MustCompile(`(?i)\d+(?:[.,]\d+)?\s*(?:ml|cl|l)\b`)
+
+var drinkIdReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+
+// AssignDrinkIds sets the ID of all drinks which have none. IDs are
+// derived from the names of the drinks so that they stay the same across
+// scrapes as long as the name does not change. Drinks with the same name
+// are numbered in the order they appear on the menu, skipping numbers
+// whose ID is already taken by another drink.
+func (t *Data) AssignDrinkIds() {
+	used := make(map[string]bool)
+	for _, drink := range t.Drinks {
+		if drink.Id != "" {
+			used[drink.Id] = true
+		}
+	}
+
+	for _, drink := range t.Drinks {
+		if drink.Id != "" {
+			continue
+		}
+		base := drinkId(drink.Name)
+		id := base
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+		drink.Id = id
+	}
+}
+
+// drinkId turns a drink name like "Apfelschorle (groß)" into an ID like
+// "apfelschorle-gross".
+func drinkId(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range drinkIdReplacer.Replace(strings.ToLower(name)) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if sb.Len() == 0 {
+		return "drink"
+	}
+	return sb.String()
+}
+
+// parseSizes parses the sizes of the drink and their prices. Sizes which
+// are already set, e.g. in a menu file, are kept and only their prices
+// are parsed. Otherwise the price text is expected to list one price per
+// size like "0,3 l 2,50 € / 0,5 l 3,50 €" or "2,50 - 3,50 €". The names
+// of the sizes are the volumes noted in the price text or description.
+// PriceCents and PriceMaxCents of the drink are set to the lowest and
+// highest price of its sizes. false is returned if any price could not
+// be parsed.
+func (t *Drink) parseSizes() bool {
+	ok := true
+	if len(t.Sizes) == 0 {
+		ok = t.sizesFromPrice()
+	} else {
+		for _, size := range t.Sizes {
+			var maxCents *int
+			ok = parsePriceInto(size.Price, &size.PriceCents, &maxCents) && ok
+		}
+	}
+
+	t.PriceCents, t.PriceMaxCents = nil, nil
+	for _, size := range t.Sizes {
+		if size.PriceCents == nil {
+			continue
+		}
+		if t.PriceCents == nil || *size.PriceCents < *t.PriceCents {
+			t.PriceCents = size.PriceCents
+		}
+		if t.PriceMaxCents == nil || *size.PriceCents > *t.PriceMaxCents {
+			t.PriceMaxCents = size.PriceCents
+		}
+	}
+	if t.PriceMaxCents != nil && *t.PriceMaxCents == *t.PriceCents {
+		t.PriceMaxCents = nil
+	}
+
+	return ok
+}
+
+func (t *Drink) sizesFromPrice() bool {
+	var prices []int
+	if strings.TrimSpace(t.Price) == "" {
+		if t.PriceCents != nil {
+			prices = append(prices, *t.PriceCents)
+		}
+		if t.PriceMaxCents != nil {
+			prices = append(prices, *t.PriceMaxCents)
+		}
+	} else {
+		prices, _ = findPrices(volumeRx.ReplaceAllString(t.Price, ""))
+	}
+
+	if len(prices) == 0 {
+		t.Sizes = []*DrinkSize{{}}
+		return false
+	}
+
+	names := volumeRx.FindAllString(t.Price, -1)
+	if len(names) != len(prices) {
+		names = volumeRx.FindAllString(t.Description, -1)
+	}
+	if len(names) != len(prices) {
+		names = defaultSizeNames(len(prices))
+	}
+
+	t.Sizes = make([]*DrinkSize, 0, len(prices))
+	for i, cents := range prices {
+		t.Sizes = append(t.Sizes, &DrinkSize{Name: strings.TrimSpace(names[i]), PriceCents: &cents})
+	}
+	return true
+}
+
+func defaultSizeNames(n int) []string {
+	switch n {
+	case 1:
+		return []string{""}
+	case 2:
+		return []string{"klein", "groß"}
+	}
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("Größe %d", i+1)
+	}
+	return names
+}
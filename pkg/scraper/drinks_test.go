@@ -0,0 +1,65 @@
+package scraper
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAssignDrinkIds(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		ids   []string
+		want  []string
+	}{
+		{
+			name:  "unique",
+			names: []string{"Cola", "Apfelschorle"},
+			want:  []string{"cola", "apfelschorle"},
+		},
+		{
+			name:  "duplicates",
+			names: []string{"Cola", "Cola", "Cola"},
+			want:  []string{"cola", "cola-2", "cola-3"},
+		},
+		{
+			name:  "numbered name",
+			names: []string{"Cola", "Cola", "Cola 2"},
+			want:  []string{"cola", "cola-2", "cola-2-2"},
+		},
+		{
+			name:  "numbered name first",
+			names: []string{"Cola 2", "Cola", "Cola"},
+			want:  []string{"cola-2", "cola", "cola-3"},
+		},
+		{
+			name:  "existing ids",
+			names: []string{"Cola", "Cola", "Wasser"},
+			ids:   []string{"", "", "cola-2"},
+			want:  []string{"cola", "cola-3", "cola-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &Data{}
+			for i, name := range tt.names {
+				drink := &Drink{Name: name}
+				if tt.ids != nil {
+					drink.Id = tt.ids[i]
+				}
+				data.Drinks = append(data.Drinks, drink)
+			}
+
+			data.AssignDrinkIds()
+
+			var ids []string
+			for _, drink := range data.Drinks {
+				ids = append(ids, drink.Id)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
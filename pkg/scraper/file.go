@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileSource reads a static menu from a JSON or YAML file instead of
+// scraping the web shop. The file has the same structure as the
+// response of GET /api/items.
+type FileSource struct {
+	file string
+}
+
+func NewFileSource(file string) *FileSource {
+	return &FileSource{file: file}
+}
+
+// Fetch reads and decodes the menu file. The file is re-read on every
+// call so that changes are picked up without a restart.
+func (t *FileSource) Fetch(_ context.Context) (*Data, error) {
+	raw, err := os.ReadFile(t.file)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(t.file)) {
+	case ".json":
+	case ".yml", ".yaml":
+		// YAML is converted to JSON first so that the json struct tags
+		// of the menu model are the only source of field names.
+		var v any
+		if err = yaml.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("failed decoding menu file: %w", err)
+		}
+		if raw, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported menu file type: %s", t.file)
+	}
+
+	var data Data
+	if err = json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed decoding menu file: %w", err)
+	}
+
+	data.AssignDrinkIds()
+	data.ParsePrices()
+	return &data, nil
+}
@@ -1,13 +1,31 @@
 package scraper
 
 type Data struct {
-	Categories []*Category `json:"categories"`
-	Drinks     []*Drink    `json:"drinks"`
+	Categories     []*Category      `json:"categories"`
+	Drinks         []*Drink         `json:"drinks"`
+	UnparsedPrices []*UnparsedPrice `json:"unparsed_prices,omitempty"`
+}
+
+type PriceKind string
+
+const (
+	PriceKindStoreItem PriceKind = "store_item"
+	PriceKindDrink     PriceKind = "drink"
+)
+
+// UnparsedPrice references a store item or drink whose price text could
+// not be parsed into cents.
+type UnparsedPrice struct {
+	Kind  PriceKind `json:"kind"`
+	Id    string    `json:"id,omitempty"`
+	Name  string    `json:"name"`
+	Price string    `json:"price"`
 }
 
 type Variant struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	SurchargeCents int    `json:"surcharge_cents,omitempty"`
 }
 
 type Category struct {
@@ -16,30 +34,76 @@ type Category struct {
 	Items []*StoreItem `json:"items"`
 }
 
+// StoreItem is an item of the shop menu. Price contains the price text as
+// shown on the web shop. PriceCents is the parsed price or the lower bound
+// of a price range in cents and is nil if the price could not be parsed.
+// PriceMaxCents is only set if Price is a range.
 type StoreItem struct {
-	Id          string     `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Price       string     `json:"price"`
-	Variants    []*Variant `json:"variants"`
-	Dips        []string   `json:"dips"`
+	Id            string     `json:"id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	Price         string     `json:"price"`
+	PriceCents    *int       `json:"price_cents"`
+	PriceMaxCents *int       `json:"price_max_cents,omitempty"`
+	Variants      []*Variant `json:"variants"`
+	Dips          []string   `json:"dips"`
 }
 
 func (t *StoreItem) VariantsContain(name string) bool {
+	return t.GetVariant(name) != nil
+}
+
+func (t *StoreItem) GetVariant(name string) *Variant {
 	for _, variant := range t.Variants {
 		if variant.Name == name {
-			return true
+			return variant
 		}
 	}
-	return false
+	return nil
 }
 
 func (t *StoreItem) IsValid() bool {
 	return t.Id != "" && t.Title != ""
 }
 
+// Drink is a drink of the menu. Id is derived from the name so that it
+// stays the same across scrapes. Sizes contains the sizes the drink is
+// available in, in the order they are listed on the menu; drinks which
+// are only available in one size have exactly one size.
 type Drink struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Price       string `json:"price"`
+	Id            string       `json:"id"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Price         string       `json:"price"`
+	PriceCents    *int         `json:"price_cents"`
+	PriceMaxCents *int         `json:"price_max_cents,omitempty"`
+	Sizes         []*DrinkSize `json:"sizes"`
+}
+
+// DrinkSize is a size a drink is available in. Name is empty if the
+// drink is only available in one size. PriceCents is nil if the price
+// could not be parsed.
+type DrinkSize struct {
+	Name       string `json:"name"`
+	Price      string `json:"price,omitempty"`
+	PriceCents *int   `json:"price_cents"`
+}
+
+// PriceForSize returns the price of the drink in cents for the given
+// size index, where 0 is the first size of the drink.
+func (t *Drink) PriceForSize(size int) (cents int, ok bool) {
+	if size < 0 || size >= len(t.Sizes) || t.Sizes[size].PriceCents == nil {
+		return 0, false
+	}
+	return *t.Sizes[size].PriceCents, true
+}
+
+// GetDrink returns the drink with the given ID or nil if there is none.
+func (t *Data) GetDrink(id string) *Drink {
+	for _, drink := range t.Drinks {
+		if drink.Id == id {
+			return drink
+		}
+	}
+	return nil
 }
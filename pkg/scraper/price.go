@@ -0,0 +1,201 @@
+package scraper
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrNoPrice = errors.New("no price found")
+
+// amountRx matches amounts like "8", "8,90", "8,9", "8.90" or "1.234,50".
+var amountRx = regexp.MustCompile(`\d{1,3}(?:\.\d{3})+(?:,\d{1,2})?|\d+(?:[.,]\d{1,2})?`)
+
+// thousandsRx matches amounts using points as thousands separators.
+var thousandsRx = regexp.MustCompile(`^\d{1,3}(?:\.\d{3})+(?:,\d{1,2})?$`)
+
+var (
+	currencyBeforeRx = regexp.MustCompile(`(?i)(?:€|\bEUR|\bEuro)\s*$`)
+	currencyAfterRx  = regexp.MustCompile(`(?i)^\s*(?:€|EUR\b|Euro\b)`)
+	rangeSepRx       = regexp.MustCompile(`(?i)^\s*(?:-|–|—|bis)\s*$`)
+	priceListRx      = regexp.MustCompile(`(?i)^(?:[\s\d.,/|–—-]|bis)*$`)
+)
+
+// surchargeRx matches surcharges like "+1,00 €" or "+ 0,50".
+var surchargeRx = regexp.MustCompile(`\+\s*(\d+(?:[.,]\d{1,2})?)`)
+
+// ParsePrice parses a price text like "8,90 €" into cents. Both German
+// decimal commas and decimal points are accepted. If the text contains
+// a price range like "8,90 - 10,50 €" or multiple prices, min is the
+// lowest and max the highest price; otherwise both are equal. Numbers
+// which are not prices, like in "ab 2 Stück 8,90 € inkl. 19% MwSt.",
+// are ignored, see findPrices.
+func ParsePrice(s string) (min, max int, err error) {
+	prices, err := findPrices(s)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i, cents := range prices {
+		if i == 0 || cents < min {
+			min = cents
+		}
+		if i == 0 || cents > max {
+			max = cents
+		}
+	}
+
+	return min, max, nil
+}
+
+// findPrices returns the prices in cents in the given text in the order
+// they appear. Only amounts next to a currency sign or name are prices,
+// together with the amounts joined to them as range like "8,90 - 10,50 €".
+// If the text contains no currency at all, it must consist of nothing
+// but amounts and separators, like "2,50" or "2,90 / 3,90".
+func findPrices(s string) ([]int, error) {
+	locs := amountRx.FindAllStringIndex(s, -1)
+	if len(locs) == 0 {
+		return nil, ErrNoPrice
+	}
+
+	isPrice := make([]bool, len(locs))
+	anyCurrency := false
+	for i, loc := range locs {
+		isPrice[i] = currencyBeforeRx.MatchString(s[:loc[0]]) || currencyAfterRx.MatchString(s[loc[1]:])
+		anyCurrency = anyCurrency || isPrice[i]
+	}
+
+	if anyCurrency {
+		// Ranges have the currency only after the upper bound, so the
+		// mark is passed on to the joined amounts in both directions.
+		for changed := true; changed; {
+			changed = false
+			for i := 0; i+1 < len(locs); i++ {
+				if isPrice[i] != isPrice[i+1] && rangeSepRx.MatchString(s[locs[i][1]:locs[i+1][0]]) {
+					isPrice[i], isPrice[i+1] = true, true
+					changed = true
+				}
+			}
+		}
+	} else {
+		if !priceListRx.MatchString(s) {
+			return nil, ErrNoPrice
+		}
+		for i := range isPrice {
+			isPrice[i] = true
+		}
+	}
+
+	var prices []int
+	for i, loc := range locs {
+		if !isPrice[i] {
+			continue
+		}
+		cents, err := parseCents(s[loc[0]:loc[1]])
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, cents)
+	}
+	return prices, nil
+}
+
+// ParseSurcharge returns the surcharge in cents noted in a variant or dip
+// text like "Käse (+1,00 €)". If the text contains no surcharge or it can
+// not be parsed, 0 is returned.
+func ParseSurcharge(s string) int {
+	m := surchargeRx.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	cents, err := parseCents(m[1])
+	if err != nil {
+		return 0
+	}
+	return cents
+}
+
+func parseCents(s string) (int, error) {
+	if thousandsRx.MatchString(s) {
+		s = strings.ReplaceAll(s, ".", "")
+	}
+	euros, fraction, _ := strings.Cut(strings.ReplaceAll(s, ",", "."), ".")
+
+	cents, err := strconv.Atoi(euros)
+	if err != nil {
+		return 0, err
+	}
+	cents *= 100
+
+	if fraction != "" {
+		if len(fraction) == 1 {
+			fraction += "0"
+		}
+		f, err := strconv.Atoi(fraction)
+		if err != nil {
+			return 0, err
+		}
+		cents += f
+	}
+
+	return cents, nil
+}
+
+// ParsePrices parses the price texts of all store items and drinks into
+// cents. Entries without a price text keep their already set cent values.
+// Entries whose price could not be parsed are collected in
+// UnparsedPrices. Surcharges noted in variant descriptions and the sizes
+// of drinks are parsed as well.
+func (t *Data) ParsePrices() {
+	t.UnparsedPrices = nil
+
+	for _, cat := range t.Categories {
+		for _, item := range cat.Items {
+			if !parsePriceInto(item.Price, &item.PriceCents, &item.PriceMaxCents) {
+				t.UnparsedPrices = append(t.UnparsedPrices, &UnparsedPrice{
+					Kind:  PriceKindStoreItem,
+					Id:    item.Id,
+					Name:  item.Title,
+					Price: item.Price,
+				})
+			}
+			for _, variant := range item.Variants {
+				if surcharge := ParseSurcharge(variant.Description); surcharge > 0 {
+					variant.SurchargeCents = surcharge
+				}
+			}
+		}
+	}
+
+	for _, drink := range t.Drinks {
+		if !drink.parseSizes() {
+			t.UnparsedPrices = append(t.UnparsedPrices, &UnparsedPrice{
+				Kind:  PriceKindDrink,
+				Id:    drink.Id,
+				Name:  drink.Name,
+				Price: drink.Price,
+			})
+		}
+	}
+}
+
+func parsePriceInto(price string, minCents, maxCents **int) bool {
+	if strings.TrimSpace(price) == "" {
+		return *minCents != nil
+	}
+
+	min, max, err := ParsePrice(price)
+	if err != nil {
+		*minCents, *maxCents = nil, nil
+		return false
+	}
+
+	*minCents = &min
+	*maxCents = nil
+	if max != min {
+		*maxCents = &max
+	}
+	return true
+}
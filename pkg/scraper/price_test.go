@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		in       string
+		min, max int
+		err      error
+	}{
+		{in: "8,90 €", min: 890, max: 890},
+		{in: "8,90", min: 890, max: 890},
+		{in: "8.90 EUR", min: 890, max: 890},
+		{in: "€ 8,9", min: 890, max: 890},
+		{in: "8 Euro", min: 800, max: 800},
+		{in: "8,90€", min: 890, max: 890},
+		{in: "8,90 - 10,50 €", min: 890, max: 1050},
+		{in: "8,90 – 10,50 €", min: 890, max: 1050},
+		{in: "8,90 bis 10,50 €", min: 890, max: 1050},
+		{in: "2,50 - 3,50", min: 250, max: 350},
+		{in: "2,90 € / 3,90 €", min: 290, max: 390},
+		{in: "8,90 € inkl. 19% MwSt.", min: 890, max: 890},
+		{in: "ab 2 Stück 8,90 €", min: 890, max: 890},
+		{in: "1.234,50 €", min: 123450, max: 123450},
+		{in: "1.234 €", min: 123400, max: 123400},
+		{in: "1.000,00 - 1.250,00 €", min: 100000, max: 125000},
+		{in: "", err: ErrNoPrice},
+		{in: "kostenlos", err: ErrNoPrice},
+		{in: "ab 2 Stück 8,90", err: ErrNoPrice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			min, max, err := ParsePrice(tt.in)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v (%d-%d)", tt.err, err, min, max)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if min != tt.min || max != tt.max {
+				t.Errorf("expected %d-%d, got %d-%d", tt.min, tt.max, min, max)
+			}
+		})
+	}
+}
+
+func TestParseSurcharge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{in: "Käse (+1,00 €)", want: 100},
+		{in: "mit Salsa-Dressing (+ 0,50)", want: 50},
+		{in: "ohne Zwiebeln", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseSurcharge(tt.in); got != tt.want {
+			t.Errorf("ParseSurcharge(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
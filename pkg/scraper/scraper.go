@@ -1,32 +1,62 @@
 package scraper
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// DefaultBaseURL is the address of the hermans-cafe.de web shop.
+const DefaultBaseURL = "https://hermans-cafe.de"
+
 var ignoreCategories = []string{"shop", "allergene-zusatzstoffe"}
 
-func ScrapeAll() (*Data, error) {
-	categories, err := ScrapeShop()
+// Scraper fetches the menu from the hermans-cafe.de web shop.
+type Scraper struct {
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a Scraper requesting pages relative to baseURL using the
+// given client. If client is nil, http.DefaultClient is used.
+func New(baseURL string, client *http.Client) *Scraper {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Scraper{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+}
+
+// Fetch scrapes all shop categories and drinks from the web shop.
+func (t *Scraper) Fetch(ctx context.Context) (*Data, error) {
+	return t.ScrapeAll(ctx)
+}
+
+func (t *Scraper) ScrapeAll(ctx context.Context) (*Data, error) {
+	categories, err := t.ScrapeShop(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	drinks, err := ScrapeDrinks()
+	drinks, err := t.ScrapeDrinks(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	data := &Data{Categories: categories, Drinks: drinks}
+	data.AssignDrinkIds()
+	data.ParsePrices()
 	return data, nil
 }
 
-func ScrapeShop() ([]*Category, error) {
-	doc, err := req("shop")
+func (t *Scraper) ScrapeShop(ctx context.Context) ([]*Category, error) {
+	doc, err := t.req(ctx, "shop")
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +76,7 @@ func ScrapeShop() ([]*Category, error) {
 	})
 
 	for _, cat := range categories {
-		cat.Items, err = ScrapeCategory(cat.Id)
+		cat.Items, err = t.ScrapeCategory(ctx, cat.Id)
 		if err != nil {
 			return nil, err
 		}
@@ -55,8 +85,8 @@ func ScrapeShop() ([]*Category, error) {
 	return categories, nil
 }
 
-func ScrapeCategory(category string) ([]*StoreItem, error) {
-	doc, err := req(category)
+func (t *Scraper) ScrapeCategory(ctx context.Context, category string) ([]*StoreItem, error) {
+	doc, err := t.req(ctx, category)
 	if err != nil {
 		return nil, err
 	}
@@ -96,8 +126,8 @@ func ScrapeCategory(category string) ([]*StoreItem, error) {
 	return items, nil
 }
 
-func ScrapeDrinks() ([]*Drink, error) {
-	doc, err := req("essen-trinken-gehen-braunschweig")
+func (t *Scraper) ScrapeDrinks(ctx context.Context) ([]*Drink, error) {
+	doc, err := t.req(ctx, "essen-trinken-gehen-braunschweig")
 	if err != nil {
 		return nil, err
 	}
@@ -114,18 +144,19 @@ func ScrapeDrinks() ([]*Drink, error) {
 	return drinks, nil
 }
 
-func req(path string) (*goquery.Document, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://hermans-cafe.de/%s", path), nil)
+func (t *Scraper) req(ctx context.Context, path string) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s", t.baseURL, path), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("User-Agent", `Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Safari/537.36`)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := t.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
@@ -0,0 +1,79 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden file with the parsed snapshots")
+
+const testdataDir = "testdata"
+
+func newReplayScraper() *Scraper {
+	return New(DefaultBaseURL, &http.Client{Transport: NewReplayTransport(testdataDir)})
+}
+
+func TestScrapeGolden(t *testing.T) {
+	data, err := newReplayScraper().ScrapeAll(context.Background())
+	if err != nil {
+		t.Fatalf("ScrapeAll failed: %v", err)
+	}
+
+	actual, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual = append(actual, '\n')
+
+	goldenPath := filepath.Join(testdataDir, "golden.json")
+	if *update {
+		if err = os.WriteFile(goldenPath, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("reading golden file failed: %v", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("parsed snapshots do not match %s; run with -update to review the changes\n%s", goldenPath, actual)
+	}
+}
+
+func TestScrapeCategory(t *testing.T) {
+	items, err := newReplayScraper().ScrapeCategory(context.Background(), "burger")
+	if err != nil {
+		t.Fatalf("ScrapeCategory failed: %v", err)
+	}
+
+	// The item without ID is skipped.
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	item := items[0]
+	if item.Id != "fmd750_product_242" || item.Title != "Classic Burger" || item.Price != "8,90 €" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if len(item.Variants) != 2 || item.Variants[0].Name != "ohne_zwiebeln" || item.Variants[1].Description != "mit Käse (+1,00 €)" {
+		t.Errorf("unexpected variants: %+v", item.Variants)
+	}
+	// The placeholder option of the dip select is skipped.
+	if len(item.Dips) != 2 || item.Dips[0] != "mit Majo" {
+		t.Errorf("unexpected dips: %v", item.Dips)
+	}
+}
+
+func TestScrapeCategoryMissingPage(t *testing.T) {
+	_, err := newReplayScraper().ScrapeCategory(context.Background(), "unknown")
+	if err == nil {
+		t.Fatal("expected error for missing page")
+	}
+}
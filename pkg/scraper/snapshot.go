@@ -0,0 +1,108 @@
+package scraper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RecordTransport is a http.RoundTripper which passes requests to the
+// next transport and stores every successfully fetched page as HTML
+// snapshot file in a directory. The snapshots can later be served
+// with ReplayTransport.
+type RecordTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+var _ http.RoundTripper = (*RecordTransport)(nil)
+
+// NewRecordTransport creates a RecordTransport writing snapshots to dir.
+// If next is nil, http.DefaultTransport is used.
+func NewRecordTransport(dir string, next http.RoundTripper) *RecordTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &RecordTransport{dir: dir, next: next}
+}
+
+func (t *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err = os.MkdirAll(t.dir, 0755); err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(snapshotFile(t.dir, req), body, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed writing snapshot: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ReplayTransport is a http.RoundTripper which answers requests from
+// the HTML snapshot files recorded by RecordTransport without doing
+// any network requests. Requests to pages without a snapshot are
+// answered with status 404.
+type ReplayTransport struct {
+	dir string
+}
+
+var _ http.RoundTripper = (*ReplayTransport)(nil)
+
+// NewReplayTransport creates a ReplayTransport serving snapshots from dir.
+func NewReplayTransport(dir string) *ReplayTransport {
+	return &ReplayTransport{dir: dir}
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := &http.Response{
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Request:    req,
+	}
+
+	body, err := os.ReadFile(snapshotFile(t.dir, req))
+	if errors.Is(err, os.ErrNotExist) {
+		resp.StatusCode = http.StatusNotFound
+		resp.Status = "404 Not Found"
+		resp.Body = http.NoBody
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp.StatusCode = http.StatusOK
+	resp.Status = "200 OK"
+	resp.Header.Set("Content-Type", "text/html; charset=utf-8")
+	resp.ContentLength = int64(len(body))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func snapshotFile(dir string, req *http.Request) string {
+	name := strings.Trim(req.URL.Path, "/")
+	if name == "" {
+		name = "index"
+	}
+	name = strings.ReplaceAll(name, "/", "_")
+	return filepath.Join(dir, name+".html")
+}